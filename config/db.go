@@ -33,7 +33,17 @@ func ConnectDB() {
 	}
 
 	// Auto migrate models
-	err = db.AutoMigrate(&models.Blog{}, &models.User{}, &models.Comment{})
+	err = db.AutoMigrate(
+		&models.Blog{}, &models.User{}, &models.Comment{},
+		&models.PostViewDaily{}, &models.PostStat{},
+		&models.Tag{}, &models.RelatedPost{},
+		&models.PostCollaborator{}, &models.ReviewComment{}, &models.PreviewLink{},
+		&models.PostAutosave{}, &models.CommentReaction{}, &models.ModerationSetting{},
+		&models.SpamCheck{}, &models.Report{},
+		&models.Mention{}, &models.Notification{}, &models.NotificationPreference{},
+		&models.Follow{}, &models.CommentRevision{}, &models.CommentVote{},
+		&models.MediaRendition{},
+	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
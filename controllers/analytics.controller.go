@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAnalyticsDays = 366
+
+// GetPostViews returns daily view counts of a post for the author.
+// Optional query params: from, to (YYYY-MM-DD), defaulting to the last 30 days.
+func GetPostViews(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.Where("id = ? AND user_id = ?", blogID, userID).First(&blog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	// Parse the date range
+	y, m, d := time.Now().Date()
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "from must not be after to"})
+		return
+	}
+	if to.Sub(from) > maxAnalyticsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Date range is too large"})
+		return
+	}
+
+	var rows []models.PostViewDaily
+	if err := config.DB.
+		Where("post_id = ? AND day BETWEEN ? AND ?", blog.ID, from, to).
+		Order("day asc").
+		Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve views"})
+		return
+	}
+
+	byDay := make(map[string]uint64, len(rows))
+	for _, row := range rows {
+		byDay[row.Day.Format("2006-01-02")] = row.Views
+	}
+
+	// Fill in days without views so the series has no gaps
+	var total uint64
+	days := []gin.H{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		total += byDay[key]
+		days = append(days, gin.H{"date": key, "views": byDay[key]})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id": blog.ID,
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"total":   total,
+		"days":    days,
+	})
+}
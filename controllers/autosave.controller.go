@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errAutosaveConflict = errors.New("autosave conflict")
+
+func GetAutosave(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var autosave models.PostAutosave
+	if err := config.DB.Where("post_id = ? AND user_id = ?", blogID, userID).First(&autosave).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "No autosave for this post"})
+		return
+	}
+
+	c.JSON(http.StatusOK, autosave)
+}
+
+// SaveAutosave stores the user's working copy of a post. Fields are
+// optional so clients can send only what changed; "revision" must be the
+// last revision the client saw (0 when starting a new working copy).
+// Retrying a save that already went through is answered with the current
+// revision, so debounced or retried requests don't raise false conflicts.
+func SaveAutosave(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Title    *string `json:"title"`
+		Content  *string `json:"content"`
+		Revision uint    `json:"revision"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+	if input.Title == nil && input.Content == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Nothing to save"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var autosave models.PostAutosave
+	err = config.DB.Where("post_id = ? AND user_id = ?", blog.ID, userID).First(&autosave).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// Start a new working copy from the current post
+		if input.Revision != 0 {
+			c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was committed or discarded in another session"})
+			return
+		}
+		autosave = models.PostAutosave{
+			PostID:      blog.ID,
+			UserID:      userID,
+			Title:       blog.Title,
+			Content:     blog.Content,
+			Revision:    1,
+			BaseVersion: blog.Version,
+		}
+		if input.Title != nil {
+			autosave.Title = *input.Title
+		}
+		if input.Content != nil {
+			autosave.Content = *input.Content
+		}
+		if err := config.DB.Create(&autosave).Error; err != nil {
+			c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was started in another session"})
+			return
+		}
+
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load autosave"})
+		return
+
+	default:
+		// A retried save that already landed is answered as a success
+		sameTitle := input.Title == nil || *input.Title == autosave.Title
+		sameContent := input.Content == nil || *input.Content == autosave.Content
+		if autosave.Revision == input.Revision+1 && sameTitle && sameContent {
+			break
+		}
+		if autosave.Revision != input.Revision {
+			c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was changed in another session", "autosave": autosave})
+			return
+		}
+
+		updates := map[string]interface{}{"revision": autosave.Revision + 1}
+		if input.Title != nil {
+			updates["title"] = *input.Title
+		}
+		if input.Content != nil {
+			updates["content"] = *input.Content
+		}
+
+		// Conditional on the revision so two concurrent saves can't both win
+		result := config.DB.Model(&autosave).Where("revision = ?", input.Revision).Updates(updates)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to save autosave"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was changed in another session"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision": autosave.Revision,
+		"saved_at": autosave.UpdatedAt,
+	})
+}
+
+func DiscardAutosave(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	if err := config.DB.Where("post_id = ? AND user_id = ?", blogID, userID).Delete(&models.PostAutosave{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to discard autosave"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Autosave discarded"})
+}
+
+// CommitAutosave promotes the working copy to the post. Body:
+// {"revision": n, "force": false}. Without force, the commit is refused
+// when the post was updated after the working copy was started.
+func CommitAutosave(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Revision uint `json:"revision" binding:"required"`
+		Force    bool `json:"force"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var autosave models.PostAutosave
+	if err := config.DB.Where("post_id = ? AND user_id = ?", blog.ID, userID).First(&autosave).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "No autosave for this post"})
+		return
+	}
+	if autosave.Revision != input.Revision {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was changed in another session", "autosave": autosave})
+		return
+	}
+	if autosave.Title == "" || autosave.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Title and content are required to commit"})
+		return
+	}
+	if !input.Force && blog.Version != autosave.BaseVersion {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Post was updated since this autosave started", "blog": blog})
+		return
+	}
+	if reviewRequired(blog, autosave.Title, autosave.Content, blog.Published) {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Post must be approved before publishing; unpublish it and submit it for review"})
+		return
+	}
+	updates := map[string]interface{}{
+		"title":   autosave.Title,
+		"content": autosave.Content,
+		"version": blog.Version + 1,
+	}
+	if reviewInvalidated(blog, autosave.Title, autosave.Content, blog.Published) {
+		updates["review_status"] = models.ReviewNone
+	}
+
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND revision = ?", autosave.ID, autosave.Revision).Delete(&models.PostAutosave{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAutosaveConflict
+		}
+		// Bump the version like UpdateById so open editors see the change
+		updated := tx.Model(&blog).Where("version = ?", blog.Version).Updates(updates)
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return errPostConflict
+		}
+		return services.SyncMentions(tx, models.MentionSourcePost, blog.ID, blog.UserID, blog.ID, autosave.Content, blog.Published)
+	})
+	if errors.Is(err, errAutosaveConflict) {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Autosave was changed in another session"})
+		return
+	}
+	if errors.Is(err, errPostConflict) {
+		var current models.Blog
+		config.DB.First(&current, blog.ID)
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"msg": "Post was changed by someone else", "version": current.Version, "blog": current})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to commit autosave"})
+		return
+	}
+
+	c.Header("ETag", etag(blog.Version))
+	c.JSON(http.StatusOK, gin.H{"msg": "Post updated", "blog": blog})
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxBulkPosts = 500
+
+type bulkResult struct {
+	ID    uint   `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// errBulkItem marks a per-item failure that is reported without rolling
+// back the other items.
+type errBulkItem struct{ msg string }
+
+func (e errBulkItem) Error() string { return e.msg }
+
+// BulkUpdatePosts applies one action to many posts in a single transaction.
+// Body: {"action": "publish|unpublish|delete|restore|retag|reassign",
+// "ids": [...], "tags": [...] for retag, "user_id": n for reassign}.
+// Items the user may not change are reported as failed and skipped; a
+// database error rolls back the whole batch.
+func BulkUpdatePosts(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Action string   `json:"action" binding:"required"`
+		IDs    []uint   `json:"ids" binding:"required"`
+		Tags   []string `json:"tags"`
+		UserID uint     `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+	if len(input.IDs) == 0 || len(input.IDs) > maxBulkPosts {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "ids must contain between 1 and 500 post IDs"})
+		return
+	}
+
+	// Validate action-specific arguments once, before touching any post
+	var tags []models.Tag
+	var newOwner models.User
+	switch input.Action {
+	case "publish", "unpublish", "delete", "restore":
+	case "retag":
+		if input.Tags == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "tags is required for retag"})
+			return
+		}
+		var err error
+		if tags, err = resolveTags(input.Tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+			return
+		}
+	case "reassign":
+		if err := config.DB.First(&newOwner, input.UserID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "user_id must be an existing user"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Unknown action"})
+		return
+	}
+
+	results := make([]bulkResult, 0, len(input.IDs))
+	err := services.Transaction(config.DB, func(tx *gorm.DB) error {
+		// Lock the posts so concurrent edits wait for the bulk change and
+		// see its version bump
+		var blogs []models.Blog
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", input.IDs).Find(&blogs).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.Blog, len(blogs))
+		for i := range blogs {
+			byID[blogs[i].ID] = &blogs[i]
+		}
+
+		seen := make(map[uint]bool, len(input.IDs))
+		for _, id := range input.IDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			err := applyBulkAction(tx, input.Action, byID[id], userID, tags, newOwner.ID)
+			var itemErr errBulkItem
+			switch {
+			case err == nil:
+				results = append(results, bulkResult{ID: id, OK: true})
+			case errors.As(err, &itemErr):
+				results = append(results, bulkResult{ID: id, Error: itemErr.msg})
+			default:
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Bulk update failed, no posts were changed"})
+		return
+	}
+
+	succeeded := 0
+	for _, r := range results {
+		if r.OK {
+			succeeded++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"action":    input.Action,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
+
+func applyBulkAction(tx *gorm.DB, action string, blog *models.Blog, userID uint, tags []models.Tag, newOwnerID uint) error {
+	if blog == nil {
+		return errBulkItem{"Post not found"}
+	}
+
+	// Co-authors may change state and tags; only the author may delete,
+	// restore or give the post away
+	ownerOnly := action == "delete" || action == "restore" || action == "reassign"
+	if ownerOnly && blog.UserID != userID || !ownerOnly && !canEditPost(*blog, userID) {
+		return errBulkItem{"Post not found or unauthorized"}
+	}
+
+	deleted := blog.DeletedAt.Valid
+	if deleted && action != "restore" {
+		return errBulkItem{"Post is in the trash"}
+	}
+
+	switch action {
+	case "publish":
+		if reviewWorkflowEnabled() && !blog.Published && blog.ReviewStatus != models.ReviewApproved {
+			return errBulkItem{"Post must be approved before publishing"}
+		}
+		updates := map[string]interface{}{
+			"published": true,
+			"draft":     false,
+			"version":   gorm.Expr("version + 1"),
+		}
+		if blog.PublishedAt == nil {
+			updates["published_at"] = time.Now()
+		}
+		if err := tx.Model(blog).Updates(updates).Error; err != nil {
+			return err
+		}
+		return services.NotifyPendingMentions(tx, models.MentionSourcePost, blog.ID, blog.ID)
+
+	case "unpublish":
+		updates := map[string]interface{}{
+			"published": false,
+			"draft":     true,
+			"version":   gorm.Expr("version + 1"),
+		}
+		if reviewInvalidated(*blog, blog.Title, blog.Content, false) {
+			updates["review_status"] = models.ReviewNone
+		}
+		return tx.Model(blog).Updates(updates).Error
+
+	case "delete":
+		return services.SoftDeletePost(tx, blog)
+
+	case "restore":
+		if !deleted {
+			return errBulkItem{"Post is not in the trash"}
+		}
+		return services.RestorePost(tx, blog)
+
+	case "retag":
+		if err := tx.Model(blog).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		return tx.Model(blog).Update("version", gorm.Expr("version + 1")).Error
+
+	case "reassign":
+		// The new author can't also be a collaborator on the post
+		if err := tx.Where("post_id = ? AND user_id = ?", blog.ID, newOwnerID).Delete(&models.PostCollaborator{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(blog).Updates(map[string]interface{}{
+			"user_id": newOwnerID,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+	}
+	return nil
+}
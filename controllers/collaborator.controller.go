@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Any member of the post (author or collaborator)
+func GetCollaborators(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || postRole(blog, userID) == "" {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var collaborators []models.PostCollaborator
+	if err := config.DB.
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("ID", "Username")
+		}).
+		Where("post_id = ?", blog.ID).
+		Order("created_at asc").
+		Find(&collaborators).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve collaborators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborators)
+}
+
+// Only the post author can invite collaborators
+func InviteCollaborator(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Username string `json:"username" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+	if input.Role != models.RoleCoAuthor && input.Role != models.RoleReviewer {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Role must be co-author or reviewer"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.Where("id = ? AND user_id = ?", blogID, userID).First(&blog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var invitee models.User
+	if err := config.DB.Where("username = ?", input.Username).First(&invitee).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "User not found"})
+		return
+	}
+	if invitee.ID == blog.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "The author is already a member of this post"})
+		return
+	}
+
+	var existing models.PostCollaborator
+	if err := config.DB.Where("post_id = ? AND user_id = ?", blog.ID, invitee.ID).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"msg": "User is already a collaborator"})
+		return
+	}
+
+	collaborator := models.PostCollaborator{
+		PostID:    blog.ID,
+		UserID:    invitee.ID,
+		Role:      input.Role,
+		InvitedBy: userID,
+	}
+	if err := config.DB.Create(&collaborator).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to add collaborator"})
+		return
+	}
+
+	// Respond with the public user fields only
+	collaborator.User = models.User{Model: gorm.Model{ID: invitee.ID}, Username: invitee.Username}
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// The post author can remove anyone; collaborators can remove themselves
+func RemoveCollaborator(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid user ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+	if blog.UserID != userID && uint(memberID) != userID {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Only the author can remove other collaborators"})
+		return
+	}
+
+	result := config.DB.Where("post_id = ? AND user_id = ?", blog.ID, memberID).Delete(&models.PostCollaborator{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to remove collaborator"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Collaborator not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Collaborator removed"})
+}
+
+// Private review comments, readable by the author, collaborators and editors
+func GetReviewComments(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || (postRole(blog, userID) == "" && !isEditor(userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var comments []models.ReviewComment
+	if err := config.DB.
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("ID", "Username")
+		}).
+		Where("post_id = ?", blog.ID).
+		Order("created_at asc").
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve review comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// Writable by the author and collaborators
+func CreateReviewComment(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || postRole(blog, userID) == "" {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	comment := models.ReviewComment{
+		PostID:  blog.ID,
+		UserID:  userID,
+		Kind:    models.ReviewCommentNote,
+		Content: input.Content,
+	}
+	if err := config.DB.Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to save review comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
@@ -3,18 +3,36 @@ package controllers
 import (
 	"BlogApp/config"
 	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CommentResponse struct {
-	ID       uint   `json:"id"`
-	Content  string `json:"content"`
-	PostID   uint   `json:"post_id"`
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	ID         uint                `json:"id"`
+	Content    string              `json:"content"`
+	PostID     uint                `json:"post_id"`
+	UserID     uint                `json:"user_id"`
+	Username   string              `json:"username"`
+	ParentID   *uint               `json:"parent_id"`
+	Depth      int                 `json:"depth"`
+	ReplyCount int                 `json:"reply_count"`
+	Reactions  map[string]int      `json:"reactions"`
+	Upvotes    int                 `json:"upvotes"`
+	Downvotes  int                 `json:"downvotes"`
+	Score      float64             `json:"score"`
+	Pinned     bool                `json:"pinned"`
+	Deleted    bool                `json:"deleted"`
+	EditedAt   *time.Time          `json:"edited_at"`
+	Mentions   []models.MentionRef `json:"mentions"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Replies    []CommentResponse   `json:"replies,omitempty"`
 }
 
 // authenticated user
@@ -36,8 +54,10 @@ func CreateComment(c *gin.Context) {
 
 	// Input struct with validation
 	var input struct {
-		Content string `json:"content" binding:"required"`
-		PostID  uint   `json:"post_id" binding:"required"`
+		Content  string `json:"content" binding:"required"`
+		PostID   uint   `json:"post_id" binding:"required"`
+		ParentID *uint  `json:"parent_id"` // optional: reply to this comment
+		Website  string `json:"website"`   // honeypot, must stay empty
 	}
 
 	// Bind and validate input
@@ -46,12 +66,20 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
-	// Check if the post exists
+	// Check if the post exists and the user can read it
 	var post models.Blog
 	if err := config.DB.First(&post, input.PostID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	if status, code, msg := checkCommentWrite(post, true); status != 0 {
+		c.JSON(status, gin.H{"error": msg, "code": code})
+		return
+	}
 
 	// Create the comment
 	comment := models.Comment{
@@ -60,11 +88,87 @@ func CreateComment(c *gin.Context) {
 		UserID:  userID,
 	}
 
+	// Replies must stay on the same post and within the depth limit
+	if input.ParentID != nil {
+		var parent models.Comment
+		if err := config.DB.Where("id = ? AND post_id = ?", *input.ParentID, input.PostID).First(&parent).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Parent comment not found"})
+			return
+		}
+		if parent.IsDeleted || parent.Status != models.CommentApproved {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot reply to a deleted or held comment"})
+			return
+		}
+		if parent.Depth+1 > maxCommentDepth() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reply is nested too deeply"})
+			return
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	// Score the comment for spam before anything is stored
+	submission := services.SpamSubmission{
+		Kind:     models.SpamSubjectComment,
+		UserID:   userID,
+		IP:       c.ClientIP(),
+		Content:  input.Content,
+		Honeypot: input.Website,
+	}
+	spamResult, err := services.ScoreSpam(submission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if spamResult.Action == models.SpamReject {
+		if err := services.RecordSpamCheck(config.DB, models.SpamSubjectComment, 0, submission, spamResult); err != nil {
+			log.Println("Failed to record spam check:", err)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Comment was rejected as spam"})
+		return
+	}
+
+	// Hold the comment for a moderator if the post or site asks for it
+	status, err := commentModerationStatus(post, userID, input.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if spamResult.Action == models.SpamHold {
+		status = models.CommentPending
+	}
+	comment.Status = status
+
 	// Save the comment
 	if err := config.DB.Create(&comment).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := services.RecordSpamCheck(config.DB, models.SpamSubjectComment, comment.ID, submission, spamResult); err != nil {
+		log.Println("Failed to record spam check:", err)
+	}
+	if err := syncCommentMentions(config.DB, comment); err != nil {
+		log.Println("Failed to save mentions:", err)
+	}
+	if comment.Status == models.CommentApproved {
+		if err := services.NotifyNewComment(config.DB, comment); err != nil {
+			log.Println("Failed to send comment notifications:", err)
+		}
+	}
+	comments := []models.Comment{comment}
+	if err := attachCommentMentions(comments); err != nil {
+		log.Println("Failed to load mentions:", err)
+	}
+	comment = comments[0]
+	if comment.Status == models.CommentApproved {
+		publishCommentEvent("comment.created", comment)
+	}
+
+	// Held comments are accepted but not public yet
+	if comment.Status == models.CommentPending {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Comment is awaiting moderation", "comment": comment})
+		return
+	}
 
 	// Return success
 	c.JSON(http.StatusCreated, comment)
@@ -95,7 +199,7 @@ func UpdateComment(c *gin.Context) {
 
 	// Fetch comment from DB
 	var comment models.Comment
-	if err := config.DB.First(&comment, id).Error; err != nil {
+	if err := config.DB.Where("is_deleted = ?", false).First(&comment, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
@@ -105,24 +209,143 @@ func UpdateComment(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own comment"})
 		return
 	}
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if status, code, msg := checkCommentWrite(post, false); status != 0 {
+		c.JSON(status, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	// Once the window has passed others may have replied to it, so the
+	// comment can no longer be changed
+	if window := commentEditWindow(); time.Since(comment.CreatedAt) > window {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Comments can only be edited within " + window.String() + " of posting"})
+		return
+	}
+
+	// The client must prove it edited the current version
+	expected, status, msg := ifMatchVersion(c)
+	if status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	if expected != comment.Version {
+		c.Header("ETag", etag(comment.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Comment was changed by someone else", "version": comment.Version, "comment": comment})
+		return
+	}
 
 	// Bind new content
 	var input struct {
 		Content string `json:"content" binding:"required"`
+		Website string `json:"website"` // honeypot, must stay empty
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update content
+	// Saving the same text again is not an edit
+	if input.Content == comment.Content {
+		c.Header("ETag", etag(comment.Version))
+		c.JSON(http.StatusOK, comment)
+		return
+	}
+
+	// The new text goes through the same spam filter and moderation as a
+	// new comment, so an approved comment cannot be edited into spam
+	submission := services.SpamSubmission{
+		Kind:     models.SpamSubjectComment,
+		UserID:   userID,
+		IP:       c.ClientIP(),
+		Content:  input.Content,
+		Honeypot: input.Website,
+	}
+	spamResult, err := services.ScoreSpam(submission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.RecordSpamCheck(config.DB, models.SpamSubjectComment, comment.ID, submission, spamResult); err != nil {
+		log.Println("Failed to record spam check:", err)
+	}
+	if spamResult.Action == models.SpamReject {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Comment was rejected as spam"})
+		return
+	}
+
+	// Held comments stay held; approved ones are held again when the new
+	// text would be
+	wasApproved := comment.Status == models.CommentApproved
+	if wasApproved {
+		status, err := commentModerationStatus(post, userID, input.Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if spamResult.Action == models.SpamHold {
+			status = models.CommentPending
+		}
+		comment.Status = status
+	}
+
+	// Update content, only if nobody else bumped the version meanwhile,
+	// keeping the previous text as a revision
+	previous := models.CommentRevision{CommentID: comment.ID, Version: comment.Version, Content: comment.Content}
+	now := time.Now()
 	comment.Content = input.Content
-	if err := config.DB.Save(&comment).Error; err != nil {
+	comment.Version = expected + 1
+	comment.EditedAt = &now
+	// Edits add or remove mentions; only newly mentioned users are notified
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		result := tx.Model(&comment).
+			Where("version = ?", expected).
+			Select("content", "version", "edited_at", "status", "updated_at").
+			Updates(&comment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errCommentConflict
+		}
+		if err := tx.Create(&previous).Error; err != nil {
+			return err
+		}
+		return syncCommentMentions(tx, comment)
+	})
+	if errors.Is(err, errCommentConflict) {
+		var current models.Comment
+		config.DB.First(&current, comment.ID)
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Comment was changed by someone else", "version": current.Version, "comment": current})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, comment)
+	comments := []models.Comment{comment}
+	if err := attachCommentMentions(comments); err != nil {
+		log.Println("Failed to load mentions:", err)
+	}
+	switch {
+	case comment.Status == models.CommentApproved && !comment.Hidden:
+		publishCommentEvent("comment.updated", comments[0])
+	case wasApproved && !comment.Hidden:
+		// Held again: readers must no longer see it
+		publishCommentEvent("comment.deleted", comment)
+	}
+
+	c.Header("ETag", etag(comment.Version))
+	if comment.Status == models.CommentPending {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Comment is awaiting moderation", "comment": comments[0]})
+		return
+	}
+	c.JSON(http.StatusOK, comments[0])
 }
 
 func DeleteComment(c *gin.Context) {
@@ -148,7 +371,7 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 	var comment models.Comment
-	if err := config.DB.First(&comment, id).Error; err != nil {
+	if err := config.DB.Where("is_deleted = ?", false).First(&comment, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
@@ -158,12 +381,20 @@ func DeleteComment(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own comment"})
 		return
 	}
+	if status, code, msg := checkCommentWriteOn(comment); status != 0 {
+		c.JSON(status, gin.H{"error": msg, "code": code})
+		return
+	}
 
-	// Delete comment
-	if err := config.DB.Delete(&comment).Error; err != nil {
+	// Delete comment; one with replies becomes a placeholder so the
+	// replies keep their place in the thread
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteThreadedComment(tx, &comment)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	publishCommentEvent("comment.deleted", comment)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
@@ -180,23 +411,159 @@ func GetComment(c *gin.Context) {
 	}
 
 	var comment models.Comment
-	if err := config.DB.First(&comment, uint(commentID)).Error; err != nil {
+	if err := config.DB.Scopes(visibleComments).First(&comment, uint(commentID)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, comment)
+	// A comment is only as visible as its post, and not at all while the
+	// post's comments are disabled
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil || post.CommentsDisabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	comments := []models.Comment{comment}
+	if err := attachCommentMentions(comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := attachCommentReactions(comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", etag(comment.Version))
+	c.JSON(http.StatusOK, comments[0])
 }
 
+// GetCommentHistory lists the earlier texts of a comment, oldest first,
+// followed by the current one.
+func GetCommentHistory(c *gin.Context) {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Deleted comments keep no history
+	var comment models.Comment
+	if err := config.DB.Scopes(visibleComments).Where("is_deleted = ?", false).First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	var revisions []models.CommentRevision
+	if err := config.DB.Where("comment_id = ?", comment.ID).Order("version asc").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comment_id": comment.ID,
+		"edited_at":  comment.EditedAt,
+		"current":    gin.H{"version": comment.Version, "content": comment.Content, "updated_at": comment.UpdatedAt},
+		"revisions":  revisions,
+	})
+}
+
+// Optional query params: post_id, user_id, from, to (YYYY-MM-DD),
+// sort (oldest, newest, reacted, top), cursor and limit.
 func GetAllComments(c *gin.Context) {
-	var comments []models.Comment
-	if err := config.DB.Find(&comments).Error; err != nil {
+	sort, cursor, limit, msg := commentPageParams(c)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Only comments on published public posts are listed here
+	query := config.DB.Model(&models.Comment{}).
+		Joins("JOIN blogs ON blogs.id = comments.post_id AND blogs.deleted_at IS NULL").
+		Where("blogs.published = ? AND blogs.visibility = ? AND blogs.hidden = ?", true, models.VisibilityPublic, false).
+		Where("blogs.comments_disabled = ?", false).
+		Where("comments.is_deleted = ?", false).
+		Scopes(visibleComments)
+
+	if v := c.Query("post_id"); v != "" {
+		postID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post_id"})
+			return
+		}
+		query = query.Where("comments.post_id = ?", postID)
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query = query.Where("comments.user_id = ?", userID)
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("comments.created_at >= ?", from)
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("comments.created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, comments)
+	comments, next, err := pageComments(query.Preload("User"), sort, cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := attachCommentMentions(comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := attachCommentReactions(comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := []CommentResponse{}
+	for _, comment := range comments {
+		response = append(response, toCommentResponse(comment))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":    response,
+		"total":       total,
+		"limit":       limit,
+		"next_cursor": next,
+	})
 }
+
 func GetCommentCount(c *gin.Context) {
 	postIDParam := c.Param("post_id")
 
@@ -207,13 +574,27 @@ func GetCommentCount(c *gin.Context) {
 		return
 	}
 
-	var count int64
-	if err := config.DB.Model(&models.Comment{}).
-		Where("post_id = ?", uint(postID)).
-		Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var post models.Blog
+	if err := config.DB.First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	// Comments of a post with comments disabled are not shown, so not counted
+	var count int64
+	if !post.CommentsDisabled {
+		if err := config.DB.Model(&models.Comment{}).
+			Scopes(visibleComments).
+			Where("post_id = ? AND is_deleted = ?", post.ID, false).
+			Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"post_id":        postID,
@@ -221,30 +602,111 @@ func GetCommentCount(c *gin.Context) {
 	})
 }
 
-// Get all comments for a specific post
+// Get all comments for a specific post. Top-level comments are paginated
+// with sort, cursor and limit; each page includes all of their replies.
 func GetCommentsByPost(c *gin.Context) {
-	postIDParam := c.Param("post_id")
+	postID, err := strconv.ParseUint(c.Param("post_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
 
-	var comments []models.Comment
-	if err := config.DB.
-		Preload("User").
-		Where("post_id = ?", postIDParam).
-		Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	sort, cursor, limit, msg := commentPageParams(c)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
 		return
 	}
 
-	// Map DB model to response model
-	var response []CommentResponse
-	for _, comment := range comments {
-		response = append(response, CommentResponse{
-			ID:       comment.ID,
-			Content:  comment.Content,
-			PostID:   comment.PostID,
-			UserID:   comment.UserID,
-			Username: comment.User.Username,
+	// Comments are only as visible as their post
+	var post models.Blog
+	if err := config.DB.First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	if post.CommentsDisabled {
+		c.JSON(http.StatusOK, gin.H{
+			"comments":         []CommentResponse{},
+			"total":            0,
+			"total_comments":   0,
+			"limit":            limit,
+			"next_cursor":      "",
+			"comment_settings": commentSettingsStatus(post),
 		})
+		return
+	}
+
+	var total, totalComments int64
+	if err := config.DB.Model(&models.Comment{}).
+		Scopes(visibleComments).
+		Where("post_id = ? AND parent_id IS NULL", post.ID).
+		Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := config.DB.Model(&models.Comment{}).
+		Scopes(visibleComments).
+		Where("post_id = ? AND is_deleted = ?", post.ID, false).
+		Count(&totalComments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	// The pinned comment leads the first page and is left out of the rest
+	rootQuery := config.DB.Preload("User").Scopes(visibleComments).Where("comments.post_id = ? AND comments.parent_id IS NULL", post.ID)
+	var pinned []models.Comment
+	if post.PinnedCommentID != nil {
+		rootQuery = rootQuery.Where("comments.id <> ?", *post.PinnedCommentID)
+		if cursor == nil {
+			if err := config.DB.Preload("User").Scopes(visibleComments).
+				Where("post_id = ? AND parent_id IS NULL AND is_deleted = ?", post.ID, false).
+				Limit(1).Find(&pinned, *post.PinnedCommentID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	roots, next, err := pageComments(rootQuery, sort, cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	roots = append(pinned, roots...)
+	replies, err := loadReplies(roots)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	all := append(roots, replies...)
+	if err := attachCommentMentions(all); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := attachCommentReactions(all); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// view=tree nests replies; the default is a flat list in thread order
+	thread := buildCommentThread(all)
+	if len(pinned) > 0 {
+		thread[0].Pinned = true
+	}
+	if c.Query("view") != "tree" {
+		thread = flattenCommentThread(thread)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":         thread,
+		"total":            total,
+		"total_comments":   totalComments,
+		"limit":            limit,
+		"next_cursor":      next,
+		"comment_settings": commentSettingsStatus(post),
+	})
 }
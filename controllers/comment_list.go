@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"BlogApp/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCommentLimit = 20
+	maxCommentLimit     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// commentCursor is the position after the last comment of a page. It is
+// sent to clients as opaque base64 JSON.
+type commentCursor struct {
+	CreatedAt time.Time `json:"t"`
+	Reactions int       `json:"r"`
+	Score     float64   `json:"s"`
+	ID        uint      `json:"id"`
+}
+
+// commentSort describes a keyset ordering: the ORDER BY clause and the
+// condition selecting the rows that come after a cursor.
+type commentSort struct {
+	order string
+	after func(cur commentCursor) (string, []interface{})
+}
+
+var commentSorts = map[string]commentSort{
+	"oldest": {
+		order: "comments.created_at asc, comments.id asc",
+		after: func(cur commentCursor) (string, []interface{}) {
+			return "comments.created_at > ? OR (comments.created_at = ? AND comments.id > ?)",
+				[]interface{}{cur.CreatedAt, cur.CreatedAt, cur.ID}
+		},
+	},
+	"newest": {
+		order: "comments.created_at desc, comments.id desc",
+		after: func(cur commentCursor) (string, []interface{}) {
+			return "comments.created_at < ? OR (comments.created_at = ? AND comments.id < ?)",
+				[]interface{}{cur.CreatedAt, cur.CreatedAt, cur.ID}
+		},
+	},
+	"reacted": {
+		order: "comments.reaction_count desc, comments.id desc",
+		after: func(cur commentCursor) (string, []interface{}) {
+			return "comments.reaction_count < ? OR (comments.reaction_count = ? AND comments.id < ?)",
+				[]interface{}{cur.Reactions, cur.Reactions, cur.ID}
+		},
+	},
+	// top ranks by the Wilson lower bound of the upvote ratio, so a few
+	// lucky votes do not outrank a long record of good ones
+	"top": {
+		order: "comments.score desc, comments.id desc",
+		after: func(cur commentCursor) (string, []interface{}) {
+			return "comments.score < ? OR (comments.score = ? AND comments.id < ?)",
+				[]interface{}{cur.Score, cur.Score, cur.ID}
+		},
+	},
+}
+
+func encodeCommentCursor(comment models.Comment) string {
+	raw, _ := json.Marshal(commentCursor{
+		CreatedAt: comment.CreatedAt,
+		Reactions: comment.ReactionCount,
+		Score:     comment.Score,
+		ID:        comment.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCommentCursor(s string) (commentCursor, error) {
+	var cur commentCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, errInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == 0 {
+		return cur, errInvalidCursor
+	}
+	return cur, nil
+}
+
+// commentPageParams reads sort, cursor and limit from the query string.
+// It returns an error message suitable for a 400 response.
+func commentPageParams(c *gin.Context) (commentSort, *commentCursor, int, string) {
+	sortName := c.DefaultQuery("sort", "oldest")
+	sort, ok := commentSorts[sortName]
+	if !ok {
+		return sort, nil, 0, "Invalid sort, expected one of: oldest, newest, reacted, top"
+	}
+
+	limit := defaultCommentLimit
+	if l := c.Query("limit"); l != "" {
+		parsedLimit, err := strconv.Atoi(l)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxCommentLimit {
+			return sort, nil, 0, "limit must be between 1 and 100"
+		}
+		limit = parsedLimit
+	}
+
+	var cursor *commentCursor
+	if s := c.Query("cursor"); s != "" {
+		cur, err := decodeCommentCursor(s)
+		if err != nil {
+			return sort, nil, 0, "Invalid cursor"
+		}
+		cursor = &cur
+	}
+	return sort, cursor, limit, ""
+}
+
+// pageComments fetches one page of comments from query in the given order,
+// returning the cursor of the next page ("" on the last page).
+func pageComments(query *gorm.DB, sort commentSort, cursor *commentCursor, limit int) ([]models.Comment, string, error) {
+	if cursor != nil {
+		cond, args := sort.after(*cursor)
+		query = query.Where(cond, args...)
+	}
+
+	var comments []models.Comment
+	if err := query.Order(sort.order).Limit(limit + 1).Find(&comments).Error; err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next = encodeCommentCursor(comments[limit-1])
+	}
+	return comments, next, nil
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned when a post's comment settings refuse a request
+const (
+	codeCommentsDisabled = "comments_disabled"
+	codeCommentsClosed   = "comments_closed"
+	codeThreadLocked     = "thread_locked"
+)
+
+const maxCommentsCloseAfterDays = 3650
+
+// commentsClosed reports whether the post has stopped taking new comments
+// because its comment period, counted from publication, ran out. Posts
+// without a recorded publication time count from their creation.
+func commentsClosed(post models.Blog) bool {
+	if post.CommentsCloseAfterDays <= 0 {
+		return false
+	}
+	opened := post.CreatedAt
+	if post.PublishedAt != nil {
+		opened = *post.PublishedAt
+	}
+	return time.Now().After(opened.AddDate(0, 0, post.CommentsCloseAfterDays))
+}
+
+// commentSettingsStatus summarizes a post's comment settings for clients
+func commentSettingsStatus(post models.Blog) gin.H {
+	return gin.H{
+		"disabled": post.CommentsDisabled,
+		"closed":   commentsClosed(post),
+		"locked":   post.CommentsLocked,
+	}
+}
+
+// checkCommentWrite decides whether comments on the post may be created
+// (creating is true) or changed. It returns 0 when allowed, otherwise the
+// status, error code and message to refuse with.
+func checkCommentWrite(post models.Blog, creating bool) (int, string, string) {
+	switch {
+	case post.CommentsDisabled:
+		return http.StatusForbidden, codeCommentsDisabled, "Comments are disabled on this post"
+	case post.CommentsLocked:
+		return http.StatusForbidden, codeThreadLocked, "This thread is locked"
+	case creating && commentsClosed(post):
+		return http.StatusForbidden, codeCommentsClosed, "Comments are closed on this post"
+	}
+	return 0, "", ""
+}
+
+// checkCommentWriteOn applies checkCommentWrite to the post of an existing
+// comment.
+func checkCommentWriteOn(comment models.Comment) (int, string, string) {
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil {
+		return http.StatusNotFound, "", "Post not found"
+	}
+	return checkCommentWrite(post, false)
+}
+
+// UpdateCommentSettings changes whether a post takes comments. Body:
+// {"disabled": bool, "close_after_days": n}; 0 days keeps comments open.
+// Authors and co-authors only.
+func UpdateCommentSettings(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Disabled       *bool `json:"disabled"`
+		CloseAfterDays *int  `json:"close_after_days"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+	if input.CloseAfterDays != nil && (*input.CloseAfterDays < 0 || *input.CloseAfterDays > maxCommentsCloseAfterDays) {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "close_after_days must be between 0 and 3650"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Disabled != nil {
+		updates["comments_disabled"] = *input.Disabled
+	}
+	if input.CloseAfterDays != nil {
+		updates["comments_close_after_days"] = *input.CloseAfterDays
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&blog).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update comment settings"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Comment settings updated", "comments": commentSettingsStatus(blog), "blog": blog})
+}
+
+// LockComments makes a post's comment thread read-only. The post's
+// authors and site moderators can lock and unlock it, but only moderators
+// can unlock a thread a moderator locked.
+func LockComments(c *gin.Context) {
+	setCommentsLocked(c, true)
+}
+
+func UnlockComments(c *gin.Context) {
+	setCommentsLocked(c, false)
+}
+
+func setCommentsLocked(c *gin.Context, locked bool) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) && !isModerator(userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+	// Only moderators can lift, or take over, a moderator's lock, so authors
+	// cannot reopen a thread moderation closed
+	if blog.CommentsLocked && blog.CommentsLockedBy != nil && !isModerator(userID) && isModerator(*blog.CommentsLockedBy) {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "This thread was locked by a moderator"})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"comments_locked":    locked,
+		"comments_locked_by": nil,
+		"comments_locked_at": nil,
+	}
+	if locked {
+		updates["comments_locked_by"] = userID
+		updates["comments_locked_at"] = time.Now()
+	}
+	if err := config.DB.Model(&blog).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update lock"})
+		return
+	}
+
+	msg := "Comments unlocked"
+	if locked {
+		msg = "Comments locked"
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": msg, "comments": commentSettingsStatus(blog)})
+}
+
+// PinComment shows one top-level comment above all others on its post,
+// replacing any earlier pin. Body: {"comment_id": n}. Authors and
+// co-authors only.
+func PinComment(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		CommentID uint `json:"comment_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	// Only a public top-level comment of this post can be pinned
+	var comment models.Comment
+	if err := config.DB.Scopes(visibleComments).
+		Where("post_id = ? AND parent_id IS NULL AND is_deleted = ?", blog.ID, false).
+		First(&comment, input.CommentID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Only a visible top-level comment of this post can be pinned"})
+		return
+	}
+
+	if err := config.DB.Model(&blog).Update("pinned_comment_id", comment.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to pin comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Comment pinned", "pinned_comment_id": comment.ID})
+}
+
+func UnpinComment(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	if err := config.DB.Model(&blog).Update("pinned_comment_id", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to unpin comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Comment unpinned"})
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxCommentDepth is how deep replies may nest; top-level comments have
+// depth 0.
+func maxCommentDepth() int {
+	return config.GetEnvInt("COMMENT_MAX_DEPTH", 5)
+}
+
+// visibleComments limits a comment query to what readers may see: approved
+// comments that no moderator or report threshold has hidden.
+func visibleComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.status = ? AND comments.hidden = ?", models.CommentApproved, false)
+}
+
+// commentEditWindow is how long after posting a comment may still be
+// edited (COMMENT_EDIT_WINDOW).
+func commentEditWindow() time.Duration {
+	return config.GetEnvDuration("COMMENT_EDIT_WINDOW", 15*time.Minute)
+}
+
+func toCommentResponse(comment models.Comment) CommentResponse {
+	response := CommentResponse{
+		ID:        comment.ID,
+		Content:   comment.Content,
+		PostID:    comment.PostID,
+		UserID:    comment.UserID,
+		Username:  comment.User.Username,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Deleted:   comment.IsDeleted,
+		EditedAt:  comment.EditedAt,
+		Mentions:  comment.Mentions,
+		Reactions: comment.Reactions,
+		Upvotes:   comment.Upvotes,
+		Downvotes: comment.Downvotes,
+		Score:     comment.Score,
+		CreatedAt: comment.CreatedAt,
+	}
+	if comment.IsDeleted {
+		response.UserID = 0
+		response.Username = ""
+		response.Mentions = nil
+		response.Reactions = nil
+	}
+	return response
+}
+
+// loadReplies fetches every visible reply below the given comments, level
+// by level, oldest first within each level.
+func loadReplies(parents []models.Comment) ([]models.Comment, error) {
+	var all []models.Comment
+	ids := make([]uint, 0, len(parents))
+	for _, parent := range parents {
+		ids = append(ids, parent.ID)
+	}
+
+	for depth := 0; len(ids) > 0 && depth < maxCommentDepth(); depth++ {
+		var level []models.Comment
+		if err := config.DB.
+			Preload("User").
+			Scopes(visibleComments).
+			Where("parent_id IN ?", ids).
+			Order("created_at asc, id asc").
+			Find(&level).Error; err != nil {
+			return nil, err
+		}
+		all = append(all, level...)
+
+		ids = ids[:0]
+		for _, comment := range level {
+			ids = append(ids, comment.ID)
+		}
+	}
+	return all, nil
+}
+
+// buildCommentThread nests comments under their parents. The input must be
+// in display order; replies whose parent is gone are shown at the top level.
+func buildCommentThread(comments []models.Comment) []CommentResponse {
+	children := make(map[uint][]models.Comment)
+	present := make(map[uint]bool, len(comments))
+	for _, comment := range comments {
+		present[comment.ID] = true
+	}
+
+	var roots []models.Comment
+	for _, comment := range comments {
+		if comment.ParentID != nil && present[*comment.ParentID] {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var build func(comment models.Comment) CommentResponse
+	build = func(comment models.Comment) CommentResponse {
+		response := toCommentResponse(comment)
+		for _, child := range children[comment.ID] {
+			response.Replies = append(response.Replies, build(child))
+		}
+		response.ReplyCount = len(response.Replies)
+		return response
+	}
+
+	tree := []CommentResponse{}
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree
+}
+
+// flattenCommentThread lists a comment tree depth-first, so every reply
+// directly follows its parent.
+func flattenCommentThread(tree []CommentResponse) []CommentResponse {
+	flat := []CommentResponse{}
+	var walk func(nodes []CommentResponse)
+	walk = func(nodes []CommentResponse) {
+		for _, node := range nodes {
+			replies := node.Replies
+			node.Replies = nil
+			flat = append(flat, node)
+			walk(replies)
+		}
+	}
+	walk(tree)
+	return flat
+}
+
+// deleteThreadedComment soft-deletes a comment, or turns it into a
+// "[deleted]" placeholder when it still has replies. Placeholders left
+// without replies are removed as well.
+func deleteThreadedComment(tx *gorm.DB, comment *models.Comment) error {
+	var replies int64
+	if err := tx.Model(&models.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+		return err
+	}
+	if replies > 0 {
+		// The placeholder must not keep the deleted text around
+		if err := services.DeleteMentions(tx, models.MentionSourceComment, comment.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentRevision{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(comment).Updates(map[string]interface{}{
+			"content":    models.DeletedCommentContent,
+			"is_deleted": true,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+	}
+
+	if err := tx.Delete(comment).Error; err != nil {
+		return err
+	}
+	if comment.ParentID == nil {
+		return nil
+	}
+
+	var parent models.Comment
+	if err := tx.First(&parent, *comment.ParentID).Error; err != nil || !parent.IsDeleted {
+		return nil
+	}
+	return deleteThreadedComment(tx, &parent)
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// wilsonZ is the z-score of the 95% confidence level used for ranking
+const wilsonZ = 1.96
+
+// wilsonLowerBound is the lower bound of the Wilson score interval for the
+// share of upvotes: the fraction we can be fairly sure the comment earns
+// given how few or many votes it has. Comments without votes score 0.
+func wilsonLowerBound(up, down int) float64 {
+	n := float64(up + down)
+	if n == 0 {
+		return 0
+	}
+	p := float64(up) / n
+	z2 := wilsonZ * wilsonZ
+	// Rounding can take the bound of all-downvoted comments a hair below 0
+	return max(0, (p+z2/(2*n)-wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n))/(1+z2/n))
+}
+
+// refreshCommentVotes recounts a comment's votes and stores the counts and
+// score on the comment. The caller must hold the comment's row lock.
+func refreshCommentVotes(tx *gorm.DB, commentID uint) (models.Comment, error) {
+	var counts struct {
+		Up   int
+		Down int
+	}
+	if err := tx.Model(&models.CommentVote{}).
+		Select("COALESCE(SUM(CASE WHEN value > 0 THEN 1 ELSE 0 END), 0) AS up, "+
+			"COALESCE(SUM(CASE WHEN value < 0 THEN 1 ELSE 0 END), 0) AS down").
+		Where("comment_id = ?", commentID).
+		Scan(&counts).Error; err != nil {
+		return models.Comment{}, err
+	}
+
+	comment := models.Comment{Upvotes: counts.Up, Downvotes: counts.Down, Score: wilsonLowerBound(counts.Up, counts.Down)}
+	comment.ID = commentID
+	err := tx.Model(&models.Comment{}).Where("id = ?", commentID).UpdateColumns(map[string]interface{}{
+		"upvotes":   comment.Upvotes,
+		"downvotes": comment.Downvotes,
+		"score":     comment.Score,
+	}).Error
+	return comment, err
+}
+
+// attachCommentReactions fills in each comment's reaction counts by type
+func attachCommentReactions(comments []models.Comment) error {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		CommentID uint
+		Type      string
+		Count     int
+	}
+	if err := config.DB.Model(&models.CommentReaction{}).
+		Select("comment_id, type, COUNT(*) AS count").
+		Where("comment_id IN ?", ids).
+		Group("comment_id, type").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]map[string]int, len(ids))
+	for _, id := range ids {
+		counts[id] = map[string]int{}
+	}
+	for _, row := range rows {
+		counts[row.CommentID][row.Type] = row.Count
+	}
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+	}
+	return nil
+}
+
+// VoteComment sets the current user's vote on a comment. Body:
+// {"value": 1} to upvote or {"value": -1} to downvote; voting again
+// replaces the earlier vote.
+func VoteComment(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := uid.(float64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	userID := uint(floatID)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var input struct {
+		Value int `json:"value" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Value != models.VoteUp && input.Value != models.VoteDown {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "value must be 1 or -1"})
+		return
+	}
+
+	var comment models.Comment
+	if err := config.DB.Scopes(visibleComments).Where("is_deleted = ?", false).First(&comment, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if comment.UserID == userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot vote on your own comment"})
+		return
+	}
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	if status, code, msg := checkCommentWrite(post, false); status != 0 {
+		c.JSON(status, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	var updated models.Comment
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize votes on this comment so the recount is never stale
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Comment{}, comment.ID).Error; err != nil {
+			return err
+		}
+		vote := models.CommentVote{CommentID: comment.ID, UserID: userID, Value: input.Value}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "comment_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value"}),
+		}).Create(&vote).Error; err != nil {
+			return err
+		}
+		var err error
+		updated, err = refreshCommentVotes(tx, comment.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save vote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comment_id": comment.ID,
+		"value":      input.Value,
+		"upvotes":    updated.Upvotes,
+		"downvotes":  updated.Downvotes,
+		"score":      updated.Score,
+	})
+}
+
+func RemoveCommentVote(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := uid.(float64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	userID := uint(floatID)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var updated models.Comment
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Comment{}, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ? AND user_id = ?", id, userID).Delete(&models.CommentVote{}).Error; err != nil {
+			return err
+		}
+		var err error
+		updated, err = refreshCommentVotes(tx, uint(id))
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove vote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Vote removed",
+		"upvotes":   updated.Upvotes,
+		"downvotes": updated.Downvotes,
+		"score":     updated.Score,
+	})
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonLowerBound(t *testing.T) {
+	tests := []struct {
+		up, down int
+		want     float64
+	}{
+		{0, 0, 0},
+		{0, 5, 0},
+		{1, 0, 0.2065},
+		{5, 5, 0.2366},
+		{90, 10, 0.8256},
+	}
+	for _, tt := range tests {
+		if got := wilsonLowerBound(tt.up, tt.down); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("wilsonLowerBound(%d, %d) = %.4f, want %.4f", tt.up, tt.down, got, tt.want)
+		}
+	}
+}
+
+func TestWilsonLowerBoundRanking(t *testing.T) {
+	// More votes at the same ratio rank higher, and a single upvote does
+	// not beat a well-liked comment
+	order := [][2]int{{1, 0}, {10, 1}, {100, 10}, {1000, 0}}
+	for i := 1; i < len(order); i++ {
+		lo := wilsonLowerBound(order[i-1][0], order[i-1][1])
+		hi := wilsonLowerBound(order[i][0], order[i][1])
+		if lo >= hi {
+			t.Errorf("%v scored %.4f, not below %v at %.4f", order[i-1], lo, order[i], hi)
+		}
+	}
+	for up := 0; up <= 20; up++ {
+		for down := 0; down <= 20; down++ {
+			if s := wilsonLowerBound(up, down); s < 0 || s > 1 {
+				t.Errorf("wilsonLowerBound(%d, %d) = %v, outside [0, 1]", up, down, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID returns the signed-in user's ID on routes where
+// authentication is optional.
+func currentUserID(c *gin.Context) (uint, bool) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		return 0, false
+	}
+	return uint(floatID), true
+}
+
+// visitorKey identifies a reader for view deduplication: the user ID when
+// signed in, otherwise the client IP and user agent.
+func visitorKey(c *gin.Context) string {
+	if userID, ok := currentUserID(c); ok {
+		return "u:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "a:" + c.ClientIP() + "|" + c.Request.UserAgent()
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returned from a transaction when a version-guarded update matched no row
+// because someone else changed the post or comment first.
+var (
+	errPostConflict    = errors.New("post version conflict")
+	errCommentConflict = errors.New("comment version conflict")
+)
+
+// etag formats a row version as an HTTP entity tag.
+func etag(version uint) string {
+	return `"v` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ifMatchVersion reads the version a client expects from the If-Match
+// header. Updates must send it; without one it returns 428.
+func ifMatchVersion(c *gin.Context) (uint, int, string) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, http.StatusPreconditionRequired, "If-Match header with the current ETag is required"
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	tag = strings.TrimSuffix(strings.TrimPrefix(tag, `"v`), `"`)
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil {
+		return 0, http.StatusBadRequest, "Malformed If-Match header"
+	}
+	return uint(version), 0, ""
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FollowUser makes the current user follow another user and notifies them
+func FollowUser(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid user ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	if uint(targetID) == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "You cannot follow yourself"})
+		return
+	}
+	var target models.User
+	if err := config.DB.Select("id").First(&target, targetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "User not found"})
+		return
+	}
+
+	var existing int64
+	if err := config.DB.Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ?", userID, target.ID).
+		Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to follow user"})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusOK, gin.H{"msg": "Already following"})
+		return
+	}
+
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Follow{FollowerID: userID, FollowingID: target.ID}).Error; err != nil {
+			return err
+		}
+		return services.Notify(tx, models.Notification{
+			UserID:  target.ID,
+			ActorID: userID,
+			Type:    models.NotificationNewFollower,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to follow user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"msg": "Now following"})
+}
+
+func UnfollowUser(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid user ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	if err := config.DB.Where("follower_id = ? AND following_id = ?", userID, targetID).
+		Delete(&models.Follow{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to unfollow user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Unfollowed"})
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+
+	"gorm.io/gorm"
+)
+
+// attachCommentMentions fills in the resolved @mentions of each comment
+func attachCommentMentions(comments []models.Comment) error {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	refs, err := services.LoadMentions(config.DB, models.MentionSourceComment, ids)
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].Mentions = refs[comments[i].ID]
+	}
+	return nil
+}
+
+// attachPostMentions fills in the resolved @mentions of each post
+func attachPostMentions(blogs []models.Blog) error {
+	ids := make([]uint, 0, len(blogs))
+	for _, blog := range blogs {
+		ids = append(ids, blog.ID)
+	}
+	refs, err := services.LoadMentions(config.DB, models.MentionSourcePost, ids)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		blogs[i].Mentions = refs[blogs[i].ID]
+	}
+	return nil
+}
+
+// syncPostMentions updates a post's mentions after its content changed.
+// Mentioned users are only notified once the post is published. Pass the
+// transaction that changed the content, so both land together.
+func syncPostMentions(tx *gorm.DB, blog models.Blog) error {
+	return services.SyncMentions(tx, models.MentionSourcePost, blog.ID, blog.UserID, blog.ID, blog.Content, blog.Published)
+}
+
+// syncCommentMentions updates a comment's mentions after its content
+// changed. Users mentioned in a held comment are notified on approval.
+func syncCommentMentions(tx *gorm.DB, comment models.Comment) error {
+	return services.SyncMentions(tx, models.MentionSourceComment, comment.ID, comment.UserID, comment.PostID,
+		comment.Content, comment.Status == models.CommentApproved)
+}
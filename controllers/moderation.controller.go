@@ -0,0 +1,439 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// siteModerationMode returns the site-wide moderation mode, auto if it was
+// never set.
+func siteModerationMode() (string, error) {
+	var setting models.ModerationSetting
+	err := config.DB.First(&setting, 1).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ModerationAuto, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.Mode, nil
+}
+
+// commentModerationStatus decides whether a new comment is published right
+// away or held for a moderator. Post authors and co-authors are never held.
+func commentModerationStatus(post models.Blog, userID uint, content string) (string, error) {
+	if canEditPost(post, userID) {
+		return models.CommentApproved, nil
+	}
+
+	mode := post.ModerationMode
+	if mode == "" {
+		var err error
+		if mode, err = siteModerationMode(); err != nil {
+			return "", err
+		}
+	}
+
+	switch mode {
+	case models.ModerationAll:
+		return models.CommentPending, nil
+	case models.ModerationLinks:
+		if services.LinkPattern.MatchString(content) {
+			return models.CommentPending, nil
+		}
+	case models.ModerationFirstTime:
+		var approved int64
+		if err := config.DB.Model(&models.Comment{}).
+			Where("user_id = ? AND status = ?", userID, models.CommentApproved).
+			Count(&approved).Error; err != nil {
+			return "", err
+		}
+		if approved == 0 {
+			return models.CommentPending, nil
+		}
+	}
+	return models.CommentApproved, nil
+}
+
+// Moderators only. Lists held comments, oldest first. Optional query
+// params: status (pending, rejected, spam), post_id, page and limit.
+func GetModerationQueue(c *gin.Context) {
+	page := 1
+	limit := 20
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 && parsedLimit <= maxCommentLimit {
+			limit = parsedLimit
+		}
+	}
+
+	status := c.DefaultQuery("status", models.CommentPending)
+	switch status {
+	case models.CommentPending, models.CommentRejected, models.CommentSpam:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected one of: pending, rejected, spam"})
+		return
+	}
+
+	query := config.DB.Model(&models.Comment{}).Where("status = ?", status)
+	if p := c.Query("post_id"); p != "" {
+		postID, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+			return
+		}
+		query = query.Where("post_id = ?", postID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count comments"})
+		return
+	}
+
+	var comments []models.Comment
+	if err := query.
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username") }).
+		Order("created_at asc, id asc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve moderation queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":     page,
+		"limit":    limit,
+		"total":    total,
+		"comments": comments,
+	})
+}
+
+// ApproveComment publishes a held comment
+func ApproveComment(c *gin.Context) {
+	moderateComment(c, models.CommentApproved)
+}
+
+// RejectComment keeps a held comment hidden
+func RejectComment(c *gin.Context) {
+	moderateComment(c, models.CommentRejected)
+}
+
+// MarkCommentSpam hides a comment and flags it as spam
+func MarkCommentSpam(c *gin.Context) {
+	moderateComment(c, models.CommentSpam)
+}
+
+func moderateComment(c *gin.Context, decision string) {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var comment models.Comment
+	if err := config.DB.First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if comment.Status == decision {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment is already " + decision})
+		return
+	}
+	// Approving a reply whose parent is hidden would orphan it in the thread
+	if decision == models.CommentApproved && comment.ParentID != nil {
+		var parent models.Comment
+		if err := config.DB.First(&parent, *comment.ParentID).Error; err != nil || parent.Status != models.CommentApproved {
+			c.JSON(http.StatusConflict, gin.H{"error": "The parent comment is not approved"})
+			return
+		}
+	}
+
+	// Approving and marking as spam are the verdicts the spam scorer is
+	// tuned against; a plain rejection says nothing about spam
+	verdict := ""
+	switch decision {
+	case models.CommentApproved:
+		verdict = models.SpamVerdictHam
+	case models.CommentSpam:
+		verdict = models.SpamVerdictSpam
+	}
+
+	firstDecision := comment.ModeratedAt == nil
+	now := time.Now()
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		// The decision is about the text the moderator saw
+		result := tx.Model(&comment).Where("version = ?", comment.Version).Updates(map[string]interface{}{
+			"status":       decision,
+			"moderated_by": userID,
+			"moderated_at": now,
+			"version":      comment.Version + 1,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errCommentConflict
+		}
+		// Users mentioned in or replied to by a held comment hear about it
+		// once it's public, but only the first time it is approved
+		if decision == models.CommentApproved {
+			if err := services.NotifyPendingMentions(tx, models.MentionSourceComment, comment.ID, comment.PostID); err != nil {
+				return err
+			}
+			if firstDecision {
+				if err := services.NotifyNewComment(tx, comment); err != nil {
+					return err
+				}
+			}
+		}
+		if verdict == "" {
+			return nil
+		}
+		return services.RecordSpamVerdict(tx, models.SpamSubjectComment, comment.ID, verdict, userID)
+	})
+	if errors.Is(err, errCommentConflict) {
+		var current models.Comment
+		config.DB.First(&current, comment.ID)
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Comment was changed by someone else", "version": current.Version, "comment": current})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate comment"})
+		return
+	}
+	if decision == models.CommentApproved && !comment.Hidden {
+		publishCommentEvent("comment.created", comment)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment " + decision, "comment": comment})
+}
+
+// Moderators only. Lists accounts held at registration, oldest first.
+func GetHeldUsers(c *gin.Context) {
+	page := 1
+	limit := 20
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 && parsedLimit <= maxCommentLimit {
+			limit = parsedLimit
+		}
+	}
+
+	query := config.DB.Model(&models.User{}).Where("status = ?", models.UserStatusHeld)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+
+	var users []models.User
+	if err := query.
+		Select("id", "username", "email", "status", "created_at").
+		Order("created_at asc, id asc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve held users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":  page,
+		"limit": limit,
+		"total": total,
+		"users": users,
+	})
+}
+
+// ApproveUser activates an account that was held at registration
+func ApproveUser(c *gin.Context) {
+	moderateUser(c, models.UserStatusActive, models.SpamVerdictHam)
+}
+
+// MarkUserSpam disables an account that was held at registration
+func MarkUserSpam(c *gin.Context) {
+	moderateUser(c, models.UserStatusSpam, models.SpamVerdictSpam)
+}
+
+func moderateUser(c *gin.Context, status, verdict string) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var user models.User
+	if err := config.DB.First(&user, targetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Status != models.UserStatusHeld {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is not awaiting review"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("status", status).Error; err != nil {
+			return err
+		}
+		return services.RecordSpamVerdict(tx, models.SpamSubjectUser, user.ID, verdict, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User marked " + status, "user_id": user.ID, "status": user.Status})
+}
+
+// GetSpamStats compares the spam scorer's decisions with moderators'
+// verdicts, per heuristic, to guide tuning of the SPAM_* settings.
+func GetSpamStats(c *gin.Context) {
+	stats, err := services.ComputeSpamStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute spam stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Moderators only
+func GetModerationSettings(c *gin.Context) {
+	mode, err := siteModerationMode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load moderation settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"mode": mode})
+}
+
+// UpdateModerationSettings sets the site-wide mode. Body:
+// {"mode": "auto|first_time|links|all"}.
+func UpdateModerationSettings(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Mode string `json:"mode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !models.IsValidModerationMode(input.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode, expected one of: auto, first_time, links, all"})
+		return
+	}
+
+	setting := models.ModerationSetting{ID: 1, Mode: input.Mode, UpdatedBy: userID}
+	if err := config.DB.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&setting).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save moderation settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdatePostModeration overrides the site-wide moderation mode for one
+// post. Body: {"mode": "auto|first_time|links|all"}, or "" to follow the
+// site setting again.
+func UpdatePostModeration(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Mode *string `json:"mode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+	if *input.Mode != "" && !models.IsValidModerationMode(*input.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid mode, expected one of: auto, first_time, links, all or empty"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	if err := config.DB.Model(&blog).Update("moderation_mode", *input.Mode).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update moderation mode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Moderation mode updated", "moderation_mode": blog.ModerationMode})
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetNotifications lists the current user's notifications, newest first.
+// Optional query params: unread=true, page and limit.
+func GetNotifications(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	page := 1
+	limit := 20
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total, unread int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to count notifications"})
+		return
+	}
+	if err := config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to count notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := query.
+		Preload("Actor", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username") }).
+		Order("created_at desc, id desc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":          page,
+		"limit":         limit,
+		"total":         total,
+		"unread_count":  unread,
+		"notifications": notifications,
+	})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid notification ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var notification models.Notification
+	if err := config.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Notification not found"})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := config.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to mark notification as read"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Notification marked as read", "notification": notification})
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	result := config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "All notifications marked as read", "updated": result.RowsAffected})
+}
+
+// GetNotificationPreferences returns whether each notification type is on
+func GetNotificationPreferences(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	preferences, err := notificationPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdateNotificationPreferences turns notification types on or off. Body:
+// {"mention": false, "new_follower": true, ...}; omitted types keep their
+// current setting.
+func UpdateNotificationPreferences(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input map[string]bool
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+
+	rows := make([]models.NotificationPreference, 0, len(input))
+	for notificationType, enabled := range input {
+		if !models.IsValidNotificationType(notificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Unknown notification type: " + notificationType})
+			return
+		}
+		rows = append(rows, models.NotificationPreference{UserID: userID, Type: notificationType, Enabled: enabled})
+	}
+	if len(rows) > 0 {
+		if err := config.DB.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+		}).Create(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to save preferences"})
+			return
+		}
+	}
+
+	preferences, err := notificationPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "Preferences updated", "preferences": preferences})
+}
+
+// notificationPreferences maps every notification type to whether the user
+// has it on
+func notificationPreferences(userID uint) (map[string]bool, error) {
+	var rows []models.NotificationPreference
+	if err := config.DB.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, row := range rows {
+		preferences[row.Type] = row.Enabled
+	}
+	return preferences, nil
+}
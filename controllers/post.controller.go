@@ -3,10 +3,18 @@ package controllers
 import (
 	"BlogApp/config"
 	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -25,10 +33,13 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 	type BlogInput struct {
-		Title     string `json:"title" binding:"required"`
-		Content   string `json:"content" binding:"required"`
-		Published bool   `json:"published"`
-		Draft     bool   `json:"draft"`
+		Title      string   `json:"title" binding:"required"`
+		Content    string   `json:"content" binding:"required"`
+		Published  bool     `json:"published"`
+		Draft      bool     `json:"draft"`
+		Tags       []string `json:"tags"`
+		Visibility string   `json:"visibility"`
+		Password   string   `json:"password"` // required for password visibility
 	}
 	var input BlogInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -44,6 +55,10 @@ func CreatePost(c *gin.Context) {
 	} else if draft {
 		published = false
 	}
+	if published && reviewWorkflowEnabled() {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Posts must be approved before publishing; save a draft and submit it for review"})
+		return
+	}
 	blog := models.Blog{
 		Title:     input.Title,
 		Content:   input.Content,
@@ -51,18 +66,43 @@ func CreatePost(c *gin.Context) {
 		Published: published,
 		Draft:     draft,
 	}
+	if published {
+		now := time.Now()
+		blog.PublishedAt = &now
+	}
+	if err := applyVisibility(&blog, input.Visibility, input.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	tags, err := resolveTags(input.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+	blog.Tags = tags
 
 	if err := config.DB.Create(&blog).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"msg": err.Error()})
 		return
 	}
+	if err := syncPostMentions(config.DB, blog); err != nil {
+		log.Println("Failed to save mentions:", err)
+	}
+	blogs := []models.Blog{blog}
+	if err := attachPostMentions(blogs); err != nil {
+		log.Println("Failed to load mentions:", err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"id":        blog.ID,
-		"title":     blog.Title,
-		"content":   blog.Content,
-		"published": blog.Published,
-		"draft":     blog.Draft,
+		"id":         blog.ID,
+		"title":      blog.Title,
+		"content":    blog.Content,
+		"published":  blog.Published,
+		"draft":      blog.Draft,
+		"visibility": blog.Visibility,
+		"tags":       blog.Tags,
+		"mentions":   blogs[0].Mentions,
 	})
 }
 
@@ -87,19 +127,34 @@ func UpdateById(c *gin.Context) {
 	}
 	userID := uint(floatID)
 
-	// Find the blog post
+	// Find the blog post; the author and co-authors may edit it
 	var blog models.Blog
-	if err := config.DB.Where("id = ? AND user_id = ?", blogID, userID).First(&blog).Error; err != nil {
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
 		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
 		return
 	}
 
+	// The client must prove it edited the current version
+	expected, status, msg := ifMatchVersion(c)
+	if status != 0 {
+		c.JSON(status, gin.H{"msg": msg})
+		return
+	}
+	if expected != blog.Version {
+		c.Header("ETag", etag(blog.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"msg": "Post was changed by someone else", "version": blog.Version, "blog": blog})
+		return
+	}
+
 	// Bind input
 	var input struct {
-		Title     string `json:"title" binding:"required"`
-		Content   string `json:"content" binding:"required"`
-		Published bool   `json:"published"`
-		Draft     bool   `json:"draft"`
+		Title      string   `json:"title" binding:"required"`
+		Content    string   `json:"content" binding:"required"`
+		Published  bool     `json:"published"`
+		Draft      bool     `json:"draft"`
+		Tags       []string `json:"tags"`       // omitted keeps the current tags
+		Visibility string   `json:"visibility"` // omitted keeps the current visibility
+		Password   string   `json:"password"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
@@ -114,16 +169,81 @@ func UpdateById(c *gin.Context) {
 	} else if draft {
 		published = false
 	}
+	if reviewRequired(blog, input.Title, input.Content, published) {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Post must be approved before publishing; unpublish it and submit it for review"})
+		return
+	}
+	if reviewInvalidated(blog, input.Title, input.Content, published) {
+		blog.ReviewStatus = models.ReviewNone
+	}
+
+	var tags []models.Tag
+	if input.Tags != nil {
+		if tags, err = resolveTags(input.Tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+			return
+		}
+	}
+
+	if err := applyVisibility(&blog, input.Visibility, input.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
 	blog.Title = input.Title
 	blog.Content = input.Content
 	blog.Published = published
 	blog.Draft = draft
+	blog.Version = expected + 1
+	if published && blog.PublishedAt == nil {
+		now := time.Now()
+		blog.PublishedAt = &now
+	}
 
-	if err := config.DB.Save(&blog).Error; err != nil {
+	// Only write if nobody else bumped the version since we loaded it. Tags
+	// are part of the post, so they change under the same version bump.
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		result := tx.Model(&blog).
+			Where("version = ?", expected).
+			Select("title", "content", "published", "published_at", "draft", "visibility", "password_hash", "review_status", "version", "updated_at").
+			Updates(&blog)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPostConflict
+		}
+		if input.Tags != nil {
+			if err := tx.Model(&blog).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
+		return syncPostMentions(tx, blog)
+	})
+	if errors.Is(err, errPostConflict) {
+		var current models.Blog
+		config.DB.First(&current, blog.ID)
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"msg": "Post was changed by someone else", "version": current.Version, "blog": current})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Update failed"})
 		return
 	}
 
+	// The update is saved; failing to load extras must not look like it
+	// wasn't
+	blogs := []models.Blog{blog}
+	if err := attachPostMentions(blogs); err != nil {
+		log.Println("Failed to load mentions:", err)
+	}
+	if err := attachPostRenditions(blogs); err != nil {
+		log.Println("Failed to load renditions:", err)
+	}
+	blog = blogs[0]
+
+	c.Header("ETag", etag(blog.Version))
 	c.JSON(http.StatusOK, gin.H{"msg": "Post updated", "blog": blog})
 }
 
@@ -155,8 +275,10 @@ func DeleteById(c *gin.Context) {
 		return
 	}
 
-	// Delete the blog post
-	if err := config.DB.Delete(&blog).Error; err != nil {
+	// Move the post and its comments to the trash
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return services.SoftDeletePost(tx, &blog)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to delete post"})
 		return
 	}
@@ -164,13 +286,22 @@ func DeleteById(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"msg": "Post deleted successfully"})
 }
 
+var postSortOrders = map[string]string{
+	"desc":      "blogs.created_at desc",
+	"asc":       "blogs.created_at asc",
+	"comments":  "COALESCE(post_stats.comments, 0) desc, blogs.created_at desc",
+	"reactions": "COALESCE(post_stats.reactions, 0) desc, blogs.created_at desc",
+	"views":     "COALESCE(post_stats.views, 0) desc, blogs.created_at desc",
+	"trending":  "COALESCE(post_stats.trending_score, 0) desc, blogs.created_at desc",
+}
+
 func GetAllPosts(c *gin.Context) {
 	page := 1
 	limit := 10
 	sort := c.DefaultQuery("sort", "desc")
 	search := c.Query("search")
 	userIDStr := c.Query("user_id")   // optional filter
-	includeDraft := c.Query("drafts") // optional: "true" to include drafts
+	includeDraft := c.Query("drafts") // optional: "true" to include your own drafts
 
 	if p := c.Query("page"); p != "" {
 		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
@@ -183,16 +314,30 @@ func GetAllPosts(c *gin.Context) {
 		}
 	}
 
+	// Sort modes other than asc/desc read the stats kept by the trending job
+	order, ok := postSortOrders[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid sort, expected one of: asc, desc, comments, reactions, views, trending"})
+		return
+	}
+
 	var blogs []models.Blog
 	var total int64
 
 	query := config.DB.Model(&models.Blog{})
 
-	// Only include drafts if requested
-	if includeDraft != "true" {
+	// Drafts are only included on request, and only the requester's own;
+	// anyone else needs a preview link to read a draft
+	if viewerID, ok := currentUserID(c); ok && includeDraft == "true" {
+		query = query.Where("published = ? OR blogs.user_id = ?", true, viewerID)
+	} else {
 		query = query.Where("published = ?", true)
 	}
 
+	// Unlisted, private, password-protected and hidden posts never appear
+	// in listings
+	query = query.Where("visibility = ? AND hidden = ?", models.VisibilityPublic, false)
+
 	// Search filter
 	if search != "" {
 		query = query.Where("title LIKE ?", "%"+search+"%")
@@ -216,15 +361,22 @@ func GetAllPosts(c *gin.Context) {
 
 	// Sorting and pagination
 	offset := (page - 1) * limit
-	order := "created_at desc"
-	if sort == "asc" {
-		order = "created_at asc"
+	if sort != "asc" && sort != "desc" {
+		query = query.Joins("LEFT JOIN post_stats ON post_stats.post_id = blogs.id")
 	}
 
-	if err := query.Order(order).Limit(limit).Offset(offset).Find(&blogs).Error; err != nil {
+	if err := query.Scopes(withPostAuthors).Preload("Tags").Order(order).Limit(limit).Offset(offset).Find(&blogs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve posts"})
 		return
 	}
+	if err := attachPostMentions(blogs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load mentions"})
+		return
+	}
+	if err := attachPostRenditions(blogs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load renditions"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"page":  page,
@@ -243,12 +395,148 @@ func GetPostById(c *gin.Context) {
 
 	var blog models.Blog
 	if err := config.DB.
-		Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("ID", "Username") // only bring username
-		}).
+		Scopes(withPostAuthors). // only bring usernames
+		Preload("Tags").
 		First(&blog, uint(blogID)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
 		return
 	}
-	c.JSON(http.StatusOK, blog)
+
+	if status, msg := checkPostAccess(c, blog); status != 0 {
+		c.JSON(status, gin.H{"msg": msg, "password_required": status == http.StatusUnauthorized})
+		return
+	}
+
+	blogs := []models.Blog{blog}
+	if err := attachPostMentions(blogs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load mentions"})
+		return
+	}
+	if err := attachPostRenditions(blogs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to load renditions"})
+		return
+	}
+
+	// Count the view; the aggregator buffers it and writes in batches
+	services.Views.Record(blog.ID, visitorKey(c))
+
+	c.Header("ETag", etag(blog.Version))
+	c.JSON(http.StatusOK, blogs[0])
+}
+
+// UnlockPost exchanges the password of a password-protected post for a
+// short-lived access cookie.
+func UnlockPost(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.Where("id = ? AND published = ? AND visibility = ?", blogID, true, models.VisibilityPassword).
+		First(&blog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(blog.PasswordHash), []byte(input.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Incorrect password"})
+		return
+	}
+
+	ttl := config.GetEnvDuration("POST_ACCESS_TTL", time.Hour)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"post_id": blog.ID,
+		"pw":      passwordFingerprint(blog),
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+	tokenString, err := token.SignedString(jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Token creation failed"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(postAccessCookieName(blog.ID), tokenString, int(ttl.Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.JSON(http.StatusOK, gin.H{"msg": "Post unlocked", "expires_in": int(ttl.Seconds())})
+}
+
+// applyVisibility validates and sets the visibility of a post. An empty
+// visibility keeps the current one (public for new posts).
+func applyVisibility(blog *models.Blog, visibility, password string) error {
+	if visibility == "" {
+		visibility = blog.Visibility
+	}
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+	}
+	if !models.IsValidVisibility(visibility) {
+		return fmt.Errorf("visibility must be one of: public, unlisted, private, password")
+	}
+
+	if visibility != models.VisibilityPassword {
+		blog.Visibility = visibility
+		blog.PasswordHash = ""
+		return nil
+	}
+
+	if password == "" {
+		if blog.PasswordHash == "" {
+			return fmt.Errorf("a password is required for password-protected posts")
+		}
+		blog.Visibility = visibility
+		return nil
+	}
+	if len(password) < 4 {
+		return fmt.Errorf("post password must be at least 4 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to process password")
+	}
+	blog.Visibility = visibility
+	blog.PasswordHash = string(hash)
+	return nil
+}
+
+const maxTagsPerPost = 10
+
+// resolveTags normalizes tag names and returns the matching tags, creating
+// the ones that don't exist yet.
+func resolveTags(names []string) ([]models.Tag, error) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if len(name) > 50 {
+			return nil, fmt.Errorf("tag %q is longer than 50 characters", name)
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > maxTagsPerPost {
+		return nil, fmt.Errorf("a post can have at most %d tags", maxTagsPerPost)
+	}
+
+	tags := []models.Tag{}
+	for _, name := range normalized {
+		tag := models.Tag{Name: name}
+		if err := config.DB.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
 }
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const roleOwner = "owner"
+
+// postRole returns how userID relates to the post: roleOwner, one of the
+// collaborator roles, or "" for everyone else.
+func postRole(blog models.Blog, userID uint) string {
+	if blog.UserID == userID {
+		return roleOwner
+	}
+	var collaborator models.PostCollaborator
+	if err := config.DB.Where("post_id = ? AND user_id = ?", blog.ID, userID).First(&collaborator).Error; err != nil {
+		return ""
+	}
+	return collaborator.Role
+}
+
+// isEditor reports whether userID has a site role that can review posts.
+func isEditor(userID uint) bool {
+	var user models.User
+	if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return user.Role == models.UserRoleEditor || user.Role == models.UserRoleAdmin
+}
+
+// isModerator reports whether userID has a site role that can moderate
+// comments.
+func isModerator(userID uint) bool {
+	var user models.User
+	if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return user.Role == models.UserRoleModerator || user.Role == models.UserRoleAdmin
+}
+
+// canEditPost reports whether userID may change the post's content.
+func canEditPost(blog models.Blog, userID uint) bool {
+	role := postRole(blog, userID)
+	return role == roleOwner || role == models.RoleCoAuthor
+}
+
+// withPostAuthors preloads the author and co-authors of posts, selecting
+// only public user fields.
+func withPostAuthors(db *gorm.DB) *gorm.DB {
+	publicUser := func(db *gorm.DB) *gorm.DB {
+		return db.Select("ID", "Username")
+	}
+	return db.
+		Preload("User", publicUser).
+		Preload("CoAuthors", "role = ?", models.RoleCoAuthor).
+		Preload("CoAuthors.User", publicUser)
+}
+
+// checkPostAccess decides whether the current request may read the post.
+// It returns 0 when allowed, otherwise the status and message to refuse with.
+func checkPostAccess(c *gin.Context, blog models.Blog) (int, string) {
+	if userID, ok := currentUserID(c); ok && postRole(blog, userID) != "" {
+		return 0, ""
+	}
+
+	// Drafts, private and hidden posts are hidden from everyone else as if
+	// missing
+	if !blog.Published || blog.Visibility == models.VisibilityPrivate || blog.Hidden {
+		return http.StatusNotFound, "Post not found"
+	}
+
+	if blog.Visibility == models.VisibilityPassword && !hasPostAccessCookie(c, blog) {
+		return http.StatusUnauthorized, "This post is password protected"
+	}
+
+	return 0, ""
+}
+
+func postAccessCookieName(postID uint) string {
+	return "post_access_" + strconv.FormatUint(uint64(postID), 10)
+}
+
+// passwordFingerprint ties access cookies to the current password, so
+// changing it locks out readers that unlocked the old one.
+func passwordFingerprint(blog models.Blog) string {
+	sum := sha256.Sum256([]byte(blog.PasswordHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+func hasPostAccessCookie(c *gin.Context, blog models.Blog) bool {
+	cookie, err := c.Cookie(postAccessCookieName(blog.ID))
+	if err != nil {
+		return false
+	}
+
+	token, err := jwt.Parse(cookie, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	postID, _ := claims["post_id"].(float64)
+	return uint(postID) == blog.ID && claims["pw"] == passwordFingerprint(blog)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachPostRenditions fills in the resized copies of each post's cover
+func attachPostRenditions(blogs []models.Blog) error {
+	keys := make([]string, 0, len(blogs))
+	for _, blog := range blogs {
+		keys = append(keys, blog.CoverImageKey)
+	}
+	renditions, err := services.LoadRenditions(config.DB, keys)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		blogs[i].CoverRenditions = renditions[blogs[i].CoverImageKey]
+	}
+	return nil
+}
+
+// UploadPostCover sets a post's cover image from the multipart field
+// "image". Resized copies are made in the background and show up in
+// cover_renditions once ready. Authors and co-authors only.
+func UploadPostCover(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	upload, status, code, msg := formUpload(c, "image", services.PostImageUpload())
+	if status != 0 {
+		c.JSON(status, gin.H{"msg": msg, "code": code})
+		return
+	}
+	if upload == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "image is required", "code": codeInvalidUpload})
+		return
+	}
+
+	if err := services.Media.Put(c.Request.Context(), upload.Key, upload.Data, upload.ContentType); err != nil {
+		log.Println("Failed to store cover image:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload image"})
+		return
+	}
+	oldKey := blog.CoverImageKey
+	if err := config.DB.Model(&blog).Updates(map[string]interface{}{
+		"cover_image":     services.Media.URL(upload.Key),
+		"cover_image_key": upload.Key,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to update cover image"})
+		return
+	}
+	releasePostCover(oldKey, upload.Key)
+	services.Images.Enqueue(upload.Key)
+
+	blogs := []models.Blog{blog}
+	if err := attachPostRenditions(blogs); err != nil {
+		log.Println("Failed to load renditions:", err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"msg":              "Cover image updated",
+		"cover_image":      blogs[0].CoverImage,
+		"cover_renditions": blogs[0].CoverRenditions,
+	})
+}
+
+func DeletePostCover(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	oldKey := blog.CoverImageKey
+	if err := config.DB.Model(&blog).Updates(map[string]interface{}{"cover_image": "", "cover_image_key": ""}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to remove cover image"})
+		return
+	}
+	releasePostCover(oldKey, "")
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Cover image removed"})
+}
+
+// releasePostCover deletes a replaced cover unless another post, including
+// one in the trash, still uses the same image.
+func releasePostCover(oldKey, newKey string) {
+	if oldKey == "" || oldKey == newKey {
+		return
+	}
+	var posts int64
+	if err := config.DB.Unscoped().Model(&models.Blog{}).Where("cover_image_key = ?", oldKey).Count(&posts).Error; err == nil && posts == 0 {
+		deleteMedia(oldKey)
+	}
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultPreviewHours = 72
+	maxPreviewHours     = 30 * 24
+)
+
+// CreatePreviewLink issues a signed, expiring link to read one post before
+// it is published. Optional body: {"expires_in_hours": 72}.
+func CreatePreviewLink(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		ExpiresInHours int `json:"expires_in_hours"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+			return
+		}
+	}
+	if input.ExpiresInHours == 0 {
+		input.ExpiresInHours = defaultPreviewHours
+	}
+	if input.ExpiresInHours < 0 || input.ExpiresInHours > maxPreviewHours {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": fmt.Sprintf("expires_in_hours must be between 1 and %d", maxPreviewHours)})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	link := models.PreviewLink{
+		PostID:    blog.ID,
+		CreatedBy: userID,
+		ExpiresAt: time.Now().Add(time.Duration(input.ExpiresInHours) * time.Hour),
+	}
+	if err := config.DB.Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create preview link"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"preview_id": link.ID,
+		"post_id":    blog.ID,
+		"exp":        link.ExpiresAt.Unix(),
+	})
+	tokenString, err := token.SignedString(jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Token creation failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         link.ID,
+		"token":      tokenString,
+		"url":        "/api/preview/" + tokenString,
+		"expires_at": link.ExpiresAt,
+	})
+}
+
+func GetPreviewLinks(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var links []models.PreviewLink
+	if err := config.DB.Where("post_id = ?", blog.ID).Order("created_at desc").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve preview links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+func RevokePreviewLink(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+	linkID, err := strconv.ParseUint(c.Param("preview_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid preview link ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+
+	var link models.PreviewLink
+	if err := config.DB.Where("id = ? AND post_id = ?", linkID, blog.ID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Preview link not found"})
+		return
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+		if err := config.DB.Save(&link).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to revoke preview link"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Preview link revoked"})
+}
+
+// Public: renders the post behind a valid, unexpired and unrevoked token
+func GetPostPreview(c *gin.Context) {
+	token, err := jwt.Parse(c.Param("token"), func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired preview link"})
+		return
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired preview link"})
+		return
+	}
+	previewID, ok1 := claims["preview_id"].(float64)
+	postID, ok2 := claims["post_id"].(float64)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired preview link"})
+		return
+	}
+
+	var link models.PreviewLink
+	if err := config.DB.Where("id = ? AND post_id = ?", uint(previewID), uint(postID)).First(&link).Error; err != nil ||
+		link.RevokedAt != nil || time.Now().After(link.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired preview link"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.
+		Scopes(withPostAuthors).
+		Preload("Tags").
+		First(&blog, link.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+	// Posts hidden by moderation stay hidden behind preview links too
+	if blog.Hidden {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview":    true,
+		"expires_at": link.ExpiresAt,
+		"post":       blog,
+	})
+}
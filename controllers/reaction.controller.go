@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshCommentReactions recounts a comment's reactions into
+// reaction_count. The caller must hold the comment's row lock.
+func refreshCommentReactions(tx *gorm.DB, commentID uint) (int, error) {
+	var count int64
+	if err := tx.Model(&models.CommentReaction{}).Where("comment_id = ?", commentID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	err := tx.Model(&models.Comment{}).Where("id = ?", commentID).UpdateColumn("reaction_count", count).Error
+	return int(count), err
+}
+
+// ReactToComment sets the current user's reaction on a comment and keeps
+// the comment's reaction_count in step.
+func ReactToComment(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := uid.(float64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	userID := uint(floatID)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var input struct {
+		Type string `json:"type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsValidReaction(input.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown reaction type"})
+		return
+	}
+
+	var comment models.Comment
+	if err := config.DB.Scopes(visibleComments).Where("is_deleted = ?", false).First(&comment, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	var post models.Blog
+	if err := config.DB.First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	if status, code, msg := checkCommentWrite(post, false); status != 0 {
+		c.JSON(status, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	var count int
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize reactions on this comment so the recount is never stale
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Comment{}, comment.ID).Error; err != nil {
+			return err
+		}
+		reaction := models.CommentReaction{CommentID: comment.ID, UserID: userID, Type: input.Type}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "comment_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"type"}),
+		}).Create(&reaction).Error; err != nil {
+			return err
+		}
+		var err error
+		count, err = refreshCommentReactions(tx, comment.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comment_id": comment.ID, "type": input.Type, "reaction_count": count})
+}
+
+func RemoveCommentReaction(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := uid.(float64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	userID := uint(floatID)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var count int
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Comment{}, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ? AND user_id = ?", id, userID).Delete(&models.CommentReaction{}).Error; err != nil {
+			return err
+		}
+		var err error
+		count, err = refreshCommentReactions(tx, uint(id))
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed", "reaction_count": count})
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRelatedPosts returns published posts similar to the given one, as
+// ranked by the related-posts job. Optional query param: limit (max 10).
+func GetRelatedPosts(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	limit := 5
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 && parsedLimit <= 10 {
+			limit = parsedLimit
+		}
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, blog); status != 0 {
+		c.JSON(status, gin.H{"msg": msg})
+		return
+	}
+
+	// Rankings may be older than the posts they point to, so drafts,
+	// non-public and deleted posts are filtered again here
+	var related []models.Blog
+	if err := config.DB.
+		Joins("JOIN related_posts ON related_posts.related_id = blogs.id").
+		Where("related_posts.post_id = ? AND blogs.published = ? AND blogs.visibility = ? AND blogs.hidden = ?",
+			blog.ID, true, models.VisibilityPublic, false).
+		Scopes(withPostAuthors).
+		Preload("Tags").
+		Order("related_posts.score desc").
+		Limit(limit).
+		Find(&related).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve related posts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id": blog.ID,
+		"posts":   related,
+	})
+}
@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxReportNoteLength = 1000
+
+// reportAutoHideThreshold is how many open reports hide a post or comment
+// until a moderator looks at it (REPORT_AUTO_HIDE_THRESHOLD).
+func reportAutoHideThreshold() int {
+	return config.GetEnvInt("REPORT_AUTO_HIDE_THRESHOLD", 5)
+}
+
+// reportTargetQuery selects a reported post or comment for an update. Users
+// can't be hidden, so it returns nil for user targets.
+func reportTargetQuery(tx *gorm.DB, targetType string, targetID uint) *gorm.DB {
+	switch targetType {
+	case models.ReportTargetPost:
+		return tx.Model(&models.Blog{}).Where("id = ?", targetID)
+	case models.ReportTargetComment:
+		return tx.Model(&models.Comment{}).Where("id = ?", targetID)
+	}
+	return nil
+}
+
+// autoHideReportTarget hides a post or comment that reached the report
+// threshold, remembering that reports hid it. Content a moderator already
+// hid stays theirs.
+func autoHideReportTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	query := reportTargetQuery(tx, targetType, targetID)
+	if query == nil {
+		return nil
+	}
+	return query.Where("hidden = ?", false).
+		Updates(map[string]interface{}{"hidden": true, "hidden_by_reports": true}).Error
+}
+
+// hideReportTarget hides a post or comment on a moderator's decision.
+func hideReportTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	query := reportTargetQuery(tx, targetType, targetID)
+	if query == nil {
+		return nil
+	}
+	return query.Updates(map[string]interface{}{"hidden": true, "hidden_by_reports": false}).Error
+}
+
+// unhideReportTarget undoes an automatic hide once its reports are
+// dismissed. Content a moderator hid is left hidden.
+func unhideReportTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	query := reportTargetQuery(tx, targetType, targetID)
+	if query == nil {
+		return nil
+	}
+	return query.Where("hidden_by_reports = ?", true).
+		Updates(map[string]interface{}{"hidden": false, "hidden_by_reports": false}).Error
+}
+
+// reportTargetOwner returns the user responsible for a reported target:
+// the user itself, or the author of the post or comment.
+func reportTargetOwner(tx *gorm.DB, targetType string, targetID uint) (uint, error) {
+	switch targetType {
+	case models.ReportTargetPost:
+		var blog models.Blog
+		err := tx.Select("id", "user_id").First(&blog, targetID).Error
+		return blog.UserID, err
+	case models.ReportTargetComment:
+		var comment models.Comment
+		err := tx.Select("id", "user_id").First(&comment, targetID).Error
+		return comment.UserID, err
+	}
+	return targetID, nil
+}
+
+// CreateReport flags a post, comment or user for moderators. Body:
+// {"target_type": "post|comment|user", "target_id": n, "reason": "...",
+// "note": "..."}.
+func CreateReport(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+		Reason     string `json:"reason" binding:"required"`
+		Note       string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !models.IsValidReportReason(input.Reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reason, expected one of: spam, harassment, hate, sexual, violence, misinformation, other"})
+		return
+	}
+	if len(input.Note) > maxReportNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note must be at most 1000 characters"})
+		return
+	}
+
+	// Readers can only report what they can see
+	switch input.TargetType {
+	case models.ReportTargetPost:
+		var blog models.Blog
+		if err := config.DB.First(&blog, input.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return
+		}
+		if status, msg := checkPostAccess(c, blog); status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := config.DB.Scopes(visibleComments).Where("is_deleted = ?", false).First(&comment, input.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return
+		}
+		var blog models.Blog
+		if err := config.DB.First(&blog, comment.PostID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return
+		}
+		if status, msg := checkPostAccess(c, blog); status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+	case models.ReportTargetUser:
+		if input.TargetID == userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report yourself"})
+			return
+		}
+		var user models.User
+		if err := config.DB.Select("id").First(&user, input.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target_type, expected one of: post, comment, user"})
+		return
+	}
+
+	var existing int64
+	if err := config.DB.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ?", userID, input.TargetType, input.TargetID).
+		Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report"})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this"})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: userID,
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Reason:     input.Reason,
+		Note:       input.Note,
+		Status:     models.ReportOpen,
+	}
+	hidden := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+
+		// Enough open reports hide the content until a moderator decides
+		var open int64
+		if err := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open < int64(reportAutoHideThreshold()) || report.TargetType == models.ReportTargetUser {
+			return nil
+		}
+		hidden = true
+		return autoHideReportTarget(tx, report.TargetType, report.TargetID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Report submitted", "report": report, "target_hidden": hidden})
+}
+
+// Moderators only. Lists reports, oldest first. Optional query params:
+// status (open, dismissed, actioned), target_type, target_id, page and
+// limit.
+func GetReports(c *gin.Context) {
+	page := 1
+	limit := 20
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 && parsedLimit <= maxCommentLimit {
+			limit = parsedLimit
+		}
+	}
+
+	status := c.DefaultQuery("status", models.ReportOpen)
+	switch status {
+	case models.ReportOpen, models.ReportDismissed, models.ReportActioned:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected one of: open, dismissed, actioned"})
+		return
+	}
+
+	query := config.DB.Model(&models.Report{}).Where("status = ?", status)
+	if t := c.Query("target_type"); t != "" {
+		query = query.Where("target_type = ?", t)
+	}
+	if id := c.Query("target_id"); id != "" {
+		targetID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+			return
+		}
+		query = query.Where("target_id = ?", targetID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count reports"})
+		return
+	}
+
+	var reports []models.Report
+	if err := query.
+		Preload("Reporter", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username") }).
+		Order("created_at asc, id asc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+		"reports": reports,
+	})
+}
+
+// ResolveReport closes a report together with every other open report on
+// the same target. Body: {"action": "dismiss|hide|suspend", "note": "..."}.
+// Dismissing unhides content the reports hid, but not content a moderator
+// hid; suspending also hides it.
+func ResolveReport(c *gin.Context) {
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Action string `json:"action" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var report models.Report
+	if err := config.DB.First(&report, reportID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+	if report.Status != models.ReportOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report is already resolved"})
+		return
+	}
+
+	status := models.ReportActioned
+	var suspend models.User
+	switch input.Action {
+	case models.ReportActionDismiss:
+		status = models.ReportDismissed
+	case models.ReportActionHide:
+		if report.TargetType == models.ReportTargetUser {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Users cannot be hidden, suspend them instead"})
+			return
+		}
+	case models.ReportActionSuspend:
+		ownerID, err := reportTargetOwner(config.DB, report.TargetType, report.TargetID)
+		if err == nil {
+			err = config.DB.Select("id", "role", "status").First(&suspend, ownerID).Error
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "The reported user no longer exists"})
+			return
+		}
+		if suspend.Role == models.UserRoleAdmin || suspend.ID == userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This user cannot be suspended"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action, expected one of: dismiss, hide, suspend"})
+		return
+	}
+
+	now := time.Now()
+	var resolved int64
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportOpen).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"resolution":      input.Action,
+				"resolution_note": input.Note,
+				"resolved_by":     userID,
+				"resolved_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		resolved = result.RowsAffected
+
+		if input.Action == models.ReportActionDismiss {
+			return unhideReportTarget(tx, report.TargetType, report.TargetID)
+		}
+		if err := hideReportTarget(tx, report.TargetType, report.TargetID); err != nil {
+			return err
+		}
+		if input.Action == models.ReportActionSuspend {
+			return tx.Model(&suspend).Update("status", models.UserStatusSuspended).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Report resolved",
+		"action":           input.Action,
+		"reports_resolved": resolved,
+	})
+}
+
+// ReinstateUser lifts a suspension
+func ReinstateUser(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "status").First(&user, targetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Status != models.UserStatusSuspended {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is not suspended"})
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("status", models.UserStatusActive).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User reinstated", "user_id": user.ID, "status": user.Status})
+}
@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// reviewWorkflowEnabled reports whether posts need an editor's approval
+// before they can be published.
+func reviewWorkflowEnabled() bool {
+	return config.GetEnvBool("REVIEW_WORKFLOW_ENABLED", false)
+}
+
+// reviewInvalidated reports whether a change voids a post's approval: an
+// approval covers one text while it is live, so editing the title or
+// content or taking the post offline sends it back through review.
+func reviewInvalidated(blog models.Blog, title, content string, published bool) bool {
+	return blog.ReviewStatus == models.ReviewApproved &&
+		(title != blog.Title || content != blog.Content || blog.Published && !published)
+}
+
+// reviewRequired reports whether the workflow refuses a change that leaves
+// the post published: going live, or changing a live post's text, needs an
+// approval that still holds afterwards.
+func reviewRequired(blog models.Blog, title, content string, published bool) bool {
+	if !reviewWorkflowEnabled() || !published {
+		return false
+	}
+	changed := title != blog.Title || content != blog.Content
+	if blog.Published && !changed {
+		return false
+	}
+	return blog.ReviewStatus != models.ReviewApproved || reviewInvalidated(blog, title, content, published)
+}
+
+// SubmitForReview puts a post in the editors' review queue. Optional body:
+// {"note": "..."} for the reviewers.
+func SubmitForReview(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+			return
+		}
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil || !canEditPost(blog, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found or unauthorized"})
+		return
+	}
+	if blog.Published {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Post is already published"})
+		return
+	}
+	if blog.ReviewStatus == models.ReviewPending {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Post is already waiting for review"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&blog).Updates(map[string]interface{}{
+			"review_status": models.ReviewPending,
+			"reviewed_by":   nil,
+			"reviewed_at":   nil,
+		}).Error; err != nil {
+			return err
+		}
+		if input.Note == "" {
+			return nil
+		}
+		return tx.Create(&models.ReviewComment{
+			PostID:  blog.ID,
+			UserID:  userID,
+			Kind:    models.ReviewCommentSubmission,
+			Content: input.Note,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to submit post"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Post submitted for review", "review_status": blog.ReviewStatus})
+}
+
+// Editors only
+func GetReviewQueue(c *gin.Context) {
+	page := 1
+	limit := 10
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsedLimit, err := strconv.Atoi(l); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	query := config.DB.Model(&models.Blog{}).Where("review_status = ?", models.ReviewPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to count posts"})
+		return
+	}
+
+	// Oldest submissions first
+	var blogs []models.Blog
+	if err := query.
+		Scopes(withPostAuthors).
+		Preload("Tags").
+		Order("updated_at asc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&blogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve review queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":  page,
+		"limit": limit,
+		"total": total,
+		"posts": blogs,
+	})
+}
+
+// ApprovePost approves a pending post and publishes it. Optional body:
+// {"comment": "..."}.
+func ApprovePost(c *gin.Context) {
+	reviewPost(c, models.ReviewApproved)
+}
+
+// RequestChanges sends a pending post back to its authors with a required
+// {"comment": "..."} explaining what to change.
+func RequestChanges(c *gin.Context) {
+	reviewPost(c, models.ReviewChangesRequested)
+}
+
+func reviewPost(c *gin.Context, decision string) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var input struct {
+		Comment string `json:"comment"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid input"})
+			return
+		}
+	}
+	if decision == models.ReviewChangesRequested && input.Comment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "A comment is required when requesting changes"})
+		return
+	}
+
+	var blog models.Blog
+	if err := config.DB.First(&blog, blogID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found"})
+		return
+	}
+	if blog.ReviewStatus != models.ReviewPending {
+		c.JSON(http.StatusConflict, gin.H{"msg": "Post is not waiting for review"})
+		return
+	}
+	if canEditPost(blog, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "You cannot review your own post"})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"review_status": decision,
+		"reviewed_by":   userID,
+		"reviewed_at":   now,
+	}
+	kind := models.ReviewCommentChangesRequested
+	if decision == models.ReviewApproved {
+		updates["published"] = true
+		updates["draft"] = false
+		updates["version"] = blog.Version + 1
+		if blog.PublishedAt == nil {
+			updates["published_at"] = now
+		}
+		kind = models.ReviewCommentApproval
+	}
+
+	err = services.Transaction(config.DB, func(tx *gorm.DB) error {
+		// The decision is about the version the reviewer saw
+		result := tx.Model(&blog).Where("version = ? AND review_status = ?", blog.Version, models.ReviewPending).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPostConflict
+		}
+		// Mentions in a draft are announced when it goes live
+		if decision == models.ReviewApproved {
+			if err := services.NotifyPendingMentions(tx, models.MentionSourcePost, blog.ID, blog.ID); err != nil {
+				return err
+			}
+			if err := services.Notify(tx, models.Notification{
+				UserID:      blog.UserID,
+				ActorID:     userID,
+				Type:        models.NotificationPostApproved,
+				SubjectType: models.NotificationSubjectPost,
+				SubjectID:   blog.ID,
+				PostID:      blog.ID,
+			}); err != nil {
+				return err
+			}
+		}
+		if input.Comment == "" {
+			return nil
+		}
+		return tx.Create(&models.ReviewComment{
+			PostID:  blog.ID,
+			UserID:  userID,
+			Kind:    kind,
+			Content: input.Comment,
+		}).Error
+	})
+	if errors.Is(err, errPostConflict) {
+		var current models.Blog
+		config.DB.First(&current, blog.ID)
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"msg": "Post was changed during review", "version": current.Version, "blog": current})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Review saved", "blog": blog})
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CreateStreamTicket issues a ticket that lets EventSource clients, which
+// cannot send an Authorization header, open a stream as ?ticket=. Tickets
+// expire after STREAM_TICKET_TTL (default one minute) and only work on
+// streaming routes, so one leaked from a URL is of little use.
+func CreateStreamTicket(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+
+	expiresAt := time.Now().Add(config.GetEnvDuration("STREAM_TICKET_TTL", time.Minute))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"stream_user_id": uint(floatID),
+		"exp":            expiresAt.Unix(),
+	})
+	tokenString, err := token.SignedString(jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Ticket creation failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ticket": tokenString, "expires_at": expiresAt})
+}
+
+// StreamPostComments streams a post's comment events over Server-Sent
+// Events, to anyone who may read the post.
+func StreamPostComments(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var post models.Blog
+	if err := config.DB.First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if status, msg := checkPostAccess(c, post); status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	streamTopic(c, services.PostCommentsTopic(post.ID))
+}
+
+// StreamNotifications streams the current user's new notifications over
+// Server-Sent Events.
+func StreamNotifications(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+
+	streamTopic(c, services.UserNotificationsTopic(uint(floatID)))
+}
+
+// streamTopic relays a hub topic to the client until it disconnects. A
+// client too slow to keep up gets an "overflow" event and is disconnected,
+// so one stalled connection never holds up the others. On shutdown every
+// client gets a "shutdown" event instead.
+func streamTopic(c *gin.Context, topic string) {
+	if services.Realtime == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Live updates are not available"})
+		return
+	}
+	sub := services.Realtime.Subscribe(topic)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // keep nginx from buffering the stream
+
+	// Comments keep idle connections open through proxies
+	heartbeat := time.NewTicker(config.GetEnvDuration("STREAM_HEARTBEAT", 25*time.Second))
+	defer heartbeat.Stop()
+
+	c.SSEvent("ready", gin.H{"topic": topic})
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-sub.Dropped:
+			c.SSEvent("overflow", gin.H{"error": "Too many pending events, reconnect and reload"})
+			return false
+		case <-sub.Closing:
+			c.SSEvent("shutdown", gin.H{"error": "Server is restarting, reconnect"})
+			return false
+		case event := <-sub.C:
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// publishCommentEvent sends a comment to everyone watching its post.
+// Deletions only carry the comment ID.
+func publishCommentEvent(eventType string, comment models.Comment) {
+	if eventType == "comment.deleted" {
+		services.Realtime.Publish(services.PostCommentsTopic(comment.PostID), eventType, gin.H{"id": comment.ID})
+		return
+	}
+	if comment.User.ID == 0 {
+		config.DB.Select("id", "username").First(&comment.User, comment.UserID)
+	}
+	services.Realtime.Publish(services.PostCommentsTopic(comment.PostID), eventType, toCommentResponse(comment))
+}
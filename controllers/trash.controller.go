@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTrash lists the current user's deleted posts and comments
+func GetTrash(c *gin.Context) {
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blogs []models.Blog
+	if err := config.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&blogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve deleted posts"})
+		return
+	}
+
+	var comments []models.Comment
+	if err := config.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to retrieve deleted comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"retention_days": int(services.TrashRetention().Hours() / 24),
+		"posts":          blogs,
+		"comments":       comments,
+	})
+}
+
+func RestorePost(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var blog models.Blog
+	if err := config.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", blogID, userID).
+		First(&blog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found in trash"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return services.RestorePost(tx, &blog)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to restore post"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Post restored", "blog": blog})
+}
+
+func PurgePost(c *gin.Context) {
+	blogID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid post ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	// Only posts already in the trash can be purged
+	var blog models.Blog
+	if err := config.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", blogID, userID).
+		First(&blog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Post not found in trash"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return services.PurgePost(tx, blog.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to purge post"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Post permanently deleted"})
+}
+
+func RestoreComment(c *gin.Context) {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid comment ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var comment models.Comment
+	if err := config.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", commentID, userID).
+		First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Comment not found in trash"})
+		return
+	}
+
+	// A comment can't come back while its post is in the trash
+	var blog models.Blog
+	if err := config.DB.First(&blog, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"msg": "The post of this comment is deleted"})
+		return
+	}
+	// Nor can a reply come back while the comment it answers is gone
+	if comment.ParentID != nil {
+		var parent models.Comment
+		if err := config.DB.First(&parent, *comment.ParentID).Error; err != nil {
+			c.JSON(http.StatusConflict, gin.H{"msg": "The comment this replies to is deleted"})
+			return
+		}
+	}
+
+	if err := config.DB.Unscoped().Model(&comment).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to restore comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Comment restored", "comment": comment})
+}
+
+func PurgeComment(c *gin.Context) {
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": "Invalid comment ID"})
+		return
+	}
+
+	rawID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Unauthorized"})
+		return
+	}
+	floatID, ok := rawID.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+		return
+	}
+	userID := uint(floatID)
+
+	var comment models.Comment
+	if err := config.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", commentID, userID).
+		First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "Comment not found in trash"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return services.PurgeComment(tx, comment.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to purge comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"msg": "Comment permanently deleted"})
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"BlogApp/config"
+	"BlogApp/models"
+	"BlogApp/services"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned when an upload is refused
+const (
+	codeFileTooLarge    = "file_too_large"
+	codeUnsupportedType = "unsupported_file_type"
+	codeEmptyFile       = "empty_file"
+	codeInvalidUpload   = "invalid_upload"
+)
+
+// multipartOverhead leaves room for the other form fields and the
+// multipart framing around an upload.
+const multipartOverhead = 1 << 20
+
+// formUpload reads and validates the file in the given form field. It
+// returns a nil upload when the field is absent; otherwise it returns 0
+// when the file is acceptable, or the status, error code and message to
+// refuse it with. Call it before reading any other form value so the body
+// size limit applies to the whole request.
+func formUpload(c *gin.Context, field string, kind services.UploadKind) (*services.Upload, int, string, string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, kind.MaxBytes+multipartOverhead)
+
+	file, err := c.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, 0, "", ""
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, http.StatusRequestEntityTooLarge, codeFileTooLarge, "File is too large"
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, codeInvalidUpload, "Invalid upload"
+	}
+
+	upload, err := services.ReadUpload(file, kind)
+	switch {
+	case errors.Is(err, services.ErrUploadTooLarge):
+		return nil, http.StatusRequestEntityTooLarge, codeFileTooLarge, "File is too large"
+	case errors.Is(err, services.ErrUploadType):
+		return nil, http.StatusUnsupportedMediaType, codeUnsupportedType, "Only JPEG, PNG, GIF and WebP images are allowed"
+	case errors.Is(err, services.ErrUploadEmpty):
+		return nil, http.StatusBadRequest, codeEmptyFile, "File is empty"
+	case errors.Is(err, services.ErrUploadCorrupt):
+		return nil, http.StatusBadRequest, codeInvalidUpload, "File is not a valid image"
+	case err != nil:
+		return nil, http.StatusBadRequest, codeInvalidUpload, "Invalid upload"
+	}
+	return &upload, 0, "", ""
+}
+
+// deleteMedia removes a replaced file and its renditions from storage.
+// Failures only leave orphaned objects behind, so they are logged rather
+// than returned.
+func deleteMedia(key string) {
+	if key == "" {
+		return
+	}
+	if err := services.DeleteRenditions(config.DB, key); err != nil {
+		log.Println("Failed to delete renditions of", key+":", err)
+	}
+	if err := services.Media.Delete(context.Background(), key); err != nil {
+		log.Println("Failed to delete media", key+":", err)
+	}
+}
+
+// profileRenditions returns the resized copies of a user's avatar, empty
+// while they are being made
+func profileRenditions(user models.User) map[string]models.MediaRendition {
+	renditions, err := services.LoadRenditions(config.DB, []string{user.ProfileImageKey})
+	if err != nil {
+		log.Println("Failed to load renditions:", err)
+		return map[string]models.MediaRendition{}
+	}
+	if r := renditions[user.ProfileImageKey]; r != nil {
+		return r
+	}
+	return map[string]models.MediaRendition{}
+}
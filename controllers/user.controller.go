@@ -3,6 +3,8 @@ package controllers
 import (
 	"BlogApp/config"
 	"BlogApp/models"
+	"BlogApp/services"
+	"log"
 	"net/http"
 	"os"
 	"time"
@@ -20,6 +22,7 @@ func Register(c *gin.Context) {
 		Username string `json:"username" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required,min=6"`
+		Website  string `json:"website"` // honeypot, must stay empty
 	}
 
 	var input RegisterInput
@@ -35,6 +38,27 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// Score the registration for spam; suspicious accounts are held
+	submission := services.SpamSubmission{
+		Kind:     models.SpamSubjectUser,
+		IP:       c.ClientIP(),
+		Email:    input.Email,
+		Username: input.Username,
+		Honeypot: input.Website,
+	}
+	spamResult, err := services.ScoreSpam(submission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to check registration"})
+		return
+	}
+	if spamResult.Action == models.SpamReject {
+		if err := services.RecordSpamCheck(config.DB, models.SpamSubjectUser, 0, submission, spamResult); err != nil {
+			log.Println("Failed to record spam check:", err)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"msg": "Registration was rejected as spam"})
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -47,13 +71,24 @@ func Register(c *gin.Context) {
 		Username: input.Username,
 		Email:    input.Email,
 		Password: string(hashedPassword),
+		Status:   models.UserStatusActive,
+	}
+	if spamResult.Action == models.SpamHold {
+		user.Status = models.UserStatusHeld
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to create user"})
 		return
 	}
+	if err := services.RecordSpamCheck(config.DB, models.SpamSubjectUser, user.ID, submission, spamResult); err != nil {
+		log.Println("Failed to record spam check:", err)
+	}
 
+	if user.Status == models.UserStatusHeld {
+		c.JSON(http.StatusAccepted, gin.H{"msg": "Registration is awaiting review"})
+		return
+	}
 	c.JSON(http.StatusCreated, gin.H{"msg": "Registration successful"})
 }
 
@@ -80,6 +115,18 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	switch user.Status {
+	case models.UserStatusHeld:
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Account is awaiting review"})
+		return
+	case models.UserStatusSuspended:
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Account is suspended"})
+		return
+	case models.UserStatusSpam:
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Account is disabled"})
+		return
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"exp":     time.Now().Add(24 * time.Hour).Unix(),
@@ -122,6 +169,7 @@ func GetProfile(c *gin.Context) {
 		"username":     user.Username,
 		"email":        user.Email,
 		"profileImage": user.ProfileImage,
+		"renditions":   profileRenditions(user),
 	})
 }
 func UpdateProfile(c *gin.Context) {
@@ -139,20 +187,28 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Parse form-data for image
+	// The image is read first so the upload size limit covers the whole form
+	upload, status, code, msg := formUpload(c, "profileImage", services.AvatarUpload())
+	if status != 0 {
+		c.JSON(status, gin.H{"msg": msg, "code": code})
+		return
+	}
+
+	// Parse the remaining form-data
 	username := c.PostForm("username")
 	email := c.PostForm("email")
 
-	// Update profile image if uploaded
-	file, err := c.FormFile("profileImage")
-	if err == nil {
-		// Save the file locally (or to cloud)
-		path := "uploads/" + file.Filename
-		if err := c.SaveUploadedFile(file, path); err != nil {
+	// Update profile image if uploaded. Objects are named after their
+	// content, so every instance resolves the same file to the same key
+	oldImageKey := user.ProfileImageKey
+	if upload != nil {
+		if err := services.Media.Put(c.Request.Context(), upload.Key, upload.Data, upload.ContentType); err != nil {
+			log.Println("Failed to store avatar:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"msg": "Failed to upload image"})
 			return
 		}
-		user.ProfileImage = "/" + path
+		user.ProfileImage = services.Media.URL(upload.Key)
+		user.ProfileImageKey = upload.Key
 	}
 
 	if username != "" {
@@ -167,10 +223,24 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	if upload != nil {
+		services.Images.Enqueue(upload.Key)
+	}
+
+	// Identical images share one object, so the old one is only removed
+	// once no other account uses it
+	if oldImageKey != "" && oldImageKey != user.ProfileImageKey {
+		var users int64
+		if err := config.DB.Unscoped().Model(&models.User{}).Where("profile_image_key = ?", oldImageKey).Count(&users).Error; err == nil && users == 0 {
+			deleteMedia(oldImageKey)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"id":           user.ID,
 		"username":     user.Username,
 		"email":        user.Email,
 		"profileImage": user.ProfileImage,
+		"renditions":   profileRenditions(user),
 	})
 }
@@ -2,10 +2,18 @@ package main
 
 import (
 	"BlogApp/config"
+	"BlogApp/middlewares"
 	"BlogApp/routes"
+	"BlogApp/services"
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,6 +21,14 @@ import (
 
 func main() {
 	config.ConnectDB()
+	services.StartViewCounter()
+	services.StartTrendingJob()
+	services.StartRelatedJob()
+	services.StartTrashRetentionJob()
+	services.StartSpamFilter()
+	services.StartRealtime()
+	services.StartStorage()
+	services.StartImagePipeline()
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -26,8 +42,15 @@ func main() {
 		trustedList = []string{"127.0.0.1"}
 	}
 
-	r := gin.Default()
-	r.Static("/uploads", "./uploads")
+	r := gin.New()
+	r.Use(middlewares.Logger(), gin.Recovery())
+	// Local media is served by the app itself; with object storage this
+	// still serves files uploaded before the switch
+	mediaDir := "./uploads"
+	if local, ok := services.Media.(*services.LocalStorage); ok {
+		mediaDir = local.Dir
+	}
+	r.Static("/uploads", mediaDir)
 
 	if err := r.SetTrustedProxies(trustedList); err != nil {
 		log.Fatalf("Failed to set trusted proxies: %v", err)
@@ -36,13 +59,41 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"ETag"},
 		AllowCredentials: true,
 	}))
 
 	routes.RegisterBlogRoutes(r)
 	routes.RegisterUserRoutes(r)
 	routes.RegisterCommentRoutes(r)
+	routes.RegisterReviewRoutes(r)
+	routes.RegisterTrashRoutes(r)
+	routes.RegisterModerationRoutes(r)
+	routes.RegisterReportRoutes(r)
+	routes.RegisterNotificationRoutes(r)
+	routes.RegisterStreamRoutes(r)
+
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	// Open streams never finish on their own, so end them for Shutdown
+	srv.RegisterOnShutdown(services.Realtime.Shutdown)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
 
-	r.Run(":" + port)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	// Stop taking requests, let the ones in flight finish, then write the
+	// views still buffered in memory so a deploy doesn't lose them
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.GetEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
+	services.Views.Flush()
 }
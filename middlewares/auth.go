@@ -6,6 +6,9 @@ import (
 	"os"
 	"strings"
 
+	"BlogApp/config"
+	"BlogApp/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -24,25 +27,149 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the algorithm
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, ok := parseToken(tokenString)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
+		authenticate(c, claims)
+	}
+}
 
-		// Optionally set user ID in context (if needed in handler)
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("user_id", claims["user_id"])
-		}
+// authenticate lets the request through as the user in claims, unless the
+// account has been removed or is no longer active.
+func authenticate(c *gin.Context, claims jwt.MapClaims) {
+	status, err := accountStatus(claims)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"msg": "User not found"})
+		c.Abort()
+		return
+	}
+	if status != models.UserStatusActive {
+		c.JSON(http.StatusForbidden, gin.H{"msg": "Account is " + status})
+		c.Abort()
+		return
+	}
+
+	// Optionally set user ID in context (if needed in handler)
+	c.Set("user_id", claims["user_id"])
+
+	c.Next()
+}
 
+// accountStatus looks up the status of the user in claims. Tokens stay
+// valid until they expire, so suspensions are checked on every request.
+func accountStatus(claims jwt.MapClaims) (string, error) {
+	var user models.User
+	if err := config.DB.Select("id", "status").First(&user, uint(claims["user_id"].(float64))).Error; err != nil {
+		return "", err
+	}
+	return user.Status, nil
+}
+
+// OptionalAuthMiddleware sets user_id when a valid token of an active
+// account is sent, but lets anonymous requests through for public routes
+// that behave differently for signed-in users. Accounts that are removed
+// or no longer active are served as anonymous.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			if claims, ok := parseToken(strings.TrimPrefix(authHeader, "Bearer ")); ok {
+				setActiveUser(c, claims)
+			}
+		}
 		c.Next()
 	}
 }
+
+// setActiveUser sets user_id from claims if the account is still active
+func setActiveUser(c *gin.Context, claims jwt.MapClaims) {
+	if status, err := accountStatus(claims); err == nil && status == models.UserStatusActive {
+		c.Set("user_id", claims["user_id"])
+	}
+}
+
+func parseToken(tokenString string) (jwt.MapClaims, bool) {
+	token, err := parseSigned(tokenString)
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+
+	// Only login tokens carry a user_id; other tokens signed with the same
+	// secret (like post access cookies) must not authenticate a user
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := claims["user_id"].(float64); !ok {
+		return nil, false
+	}
+	return claims, true
+}
+
+func parseSigned(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the algorithm
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	})
+}
+
+// parseStreamTicket checks a stream ticket and returns its claims in the
+// shape of a login token's. Tickets carry stream_user_id instead of
+// user_id, so they never work as a login token and vice versa.
+func parseStreamTicket(ticket string) (jwt.MapClaims, bool) {
+	token, err := parseSigned(ticket)
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	userID, ok := claims["stream_user_id"].(float64)
+	if !ok {
+		return nil, false
+	}
+	return jwt.MapClaims{"user_id": userID}, true
+}
+
+// StreamAuthMiddleware is AuthMiddleware for streaming routes. Clients
+// that cannot send headers, such as the browser's EventSource, pass a
+// short-lived ticket from POST /api/stream/ticket as ?ticket= instead, so
+// the login token never appears in a URL.
+func StreamAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			auth(c)
+			return
+		}
+		claims, ok := parseStreamTicket(ticket)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid or expired ticket"})
+			c.Abort()
+			return
+		}
+		authenticate(c, claims)
+	}
+}
+
+// OptionalStreamAuthMiddleware is OptionalAuthMiddleware that also accepts
+// a stream ticket.
+func OptionalStreamAuthMiddleware() gin.HandlerFunc {
+	optional := OptionalAuthMiddleware()
+	return func(c *gin.Context) {
+		if claims, ok := parseStreamTicket(c.Query("ticket")); ok {
+			setActiveUser(c, claims)
+			c.Next()
+			return
+		}
+		optional(c)
+	}
+}
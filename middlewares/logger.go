@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are query parameters that carry credentials
+var redactedParams = []string{"ticket", "token"}
+
+// redactedPrefixes are paths whose next segment is a credential, such as
+// the token of a draft preview link
+var redactedPrefixes = []string{"/api/preview/"}
+
+// Logger is gin's request logger with credentials in the path or query
+// string replaced, so they do not end up in the logs.
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Formatter: func(p gin.LogFormatterParams) string {
+			if p.Latency > time.Minute {
+				p.Latency = p.Latency.Truncate(time.Second)
+			}
+			return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+				p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+				p.StatusCode,
+				p.Latency,
+				p.ClientIP,
+				p.Method,
+				redactQuery(redactPath(p.Path)),
+				p.ErrorMessage,
+			)
+		},
+	})
+}
+
+func redactQuery(path string) string {
+	u, err := url.Parse(path)
+	if err != nil || u.RawQuery == "" {
+		return path
+	}
+	query := u.Query()
+	redacted := false
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func redactPath(path string) string {
+	for _, prefix := range redactedPrefixes {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+		// Keep whatever follows the credential, such as the query string
+		if i := strings.IndexAny(rest, "/?"); i >= 0 {
+			return prefix + "REDACTED" + rest[i:]
+		}
+		return prefix + "REDACTED"
+	}
+	return path
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through users with one of the given site roles.
+// Admins are always allowed. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		floatID, ok := c.MustGet("user_id").(float64)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"msg": "Invalid user ID type"})
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.Select("id", "role").First(&user, uint(floatID)).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"msg": "User not found"})
+			c.Abort()
+			return
+		}
+
+		allowed := user.Role == models.UserRoleAdmin
+		for _, role := range roles {
+			if user.Role == role {
+				allowed = true
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"msg": "You do not have permission to do this"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_role", user.Role)
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PostAutosave is one user's working copy of a post. It is kept apart from
+// the post until it is committed, so autosaving never changes what readers
+// see.
+type PostAutosave struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_autosave_post_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_autosave_post_user"`
+	Title     string    `json:"title" gorm:"type:longtext;not null"`
+	Content   string    `json:"content" gorm:"type:longtext;not null"`
+
+	// Revision increases on every autosave; clients send back the last one
+	// they saw so a save from another session is detected
+	Revision uint `json:"revision" gorm:"not null;default:1"`
+
+	// BaseVersion is the post's version when this working copy started
+	BaseVersion uint `json:"base_version"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Collaborator roles on a post. Co-authors can edit the post, reviewers can
+// only read it and leave private review comments.
+const (
+	RoleCoAuthor = "co-author"
+	RoleReviewer = "reviewer"
+)
+
+type PostCollaborator struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_post_collaborator"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_post_collaborator;index"`
+	Role      string    `json:"role" gorm:"type:varchar(20);not null"`
+	InvitedBy uint      `json:"invited_by" gorm:"not null"`
+	User      User      `json:"user" gorm:"foreignKey:UserID"`
+}
@@ -1,11 +1,64 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Moderation states of a comment. Only approved comments are public.
+const (
+	CommentApproved = "approved"
+	CommentPending  = "pending"
+	CommentRejected = "rejected"
+	CommentSpam     = "spam"
+)
+
+// DeletedCommentContent replaces the content of a deleted comment that
+// still has replies, so the thread stays readable.
+const DeletedCommentContent = "[deleted]"
 
 type Comment struct {
 	gorm.Model
 	Content string `json:"content"`
 	UserID  uint   `json:"user_id"`
 	PostID  uint   `json:"post_id"`
-	User    User   `json:"user" gorm:"foreignKey:UserID"`
+	Version uint   `json:"version" gorm:"not null;default:1"`
+
+	// EditedAt is set on the first edit; earlier texts are kept as
+	// CommentRevisions
+	EditedAt *time.Time `json:"edited_at"`
+	User     User       `json:"user" gorm:"foreignKey:UserID"`
+
+	// Threading: top-level comments have no parent and depth 0
+	ParentID  *uint `json:"parent_id" gorm:"index"`
+	Depth     int   `json:"depth" gorm:"not null;default:0"`
+	IsDeleted bool  `json:"is_deleted" gorm:"not null;default:false"`
+
+	// ReactionCount mirrors the number of CommentReaction rows so comments
+	// can be sorted and paginated by it
+	ReactionCount int `json:"reaction_count" gorm:"not null;default:0;index"`
+
+	// Upvotes and Downvotes mirror the CommentVote rows; Score is the
+	// Wilson lower bound of the upvote ratio, used by the "top" sort
+	Upvotes   int     `json:"upvotes" gorm:"not null;default:0"`
+	Downvotes int     `json:"downvotes" gorm:"not null;default:0"`
+	Score     float64 `json:"score" gorm:"not null;default:0;index"`
+
+	// Moderation: held comments stay pending until a moderator decides
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:approved;index"`
+	ModeratedBy *uint      `json:"moderated_by"`
+	ModeratedAt *time.Time `json:"moderated_at"`
+
+	// Hidden is set by moderators, or automatically once enough readers
+	// report the comment (HiddenByReports), in which case dismissing the
+	// reports unhides it
+	Hidden          bool `json:"hidden" gorm:"not null;default:false;index"`
+	HiddenByReports bool `json:"-" gorm:"not null;default:false"`
+
+	// Users @mentioned in Content, filled in for responses
+	Mentions []MentionRef `json:"mentions" gorm:"-"`
+
+	// Reaction counts by type, filled in for responses
+	Reactions map[string]int `json:"reactions" gorm:"-"`
 }
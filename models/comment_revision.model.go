@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CommentRevision keeps the text a comment had before an edit. Version is
+// the comment version that text belonged to.
+type CommentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CommentID uint      `json:"comment_id" gorm:"index"`
+	Version   uint      `json:"version"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"` // when the text was replaced
+}
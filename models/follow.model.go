@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Follow means FollowerID follows FollowingID.
+type Follow struct {
+	FollowerID  uint      `json:"follower_id" gorm:"primaryKey"`
+	FollowingID uint      `json:"following_id" gorm:"primaryKey;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
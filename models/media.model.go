@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// MediaRendition is a resized copy of an uploaded image. Renditions belong
+// to the stored original (SourceKey) rather than to a user or post, so an
+// image shared by several owners is only processed once.
+type MediaRendition struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	CreatedAt   time.Time `json:"-"`
+	SourceKey   string    `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_rendition_source_name"`
+	Name        string    `json:"name" gorm:"type:varchar(20);not null;uniqueIndex:idx_rendition_source_name"`
+	Key         string    `json:"-" gorm:"column:object_key;type:varchar(255);not null"`
+	URL         string    `json:"url" gorm:"-"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(50)"`
+}
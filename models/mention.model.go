@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Where a mention was written
+const (
+	MentionSourcePost    = "post"
+	MentionSourceComment = "comment"
+)
+
+// Mention links a post or comment to a user it @mentions. Notified is set
+// once the user was told, so edits and publishing don't notify twice.
+type Mention struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SourceType  string    `json:"source_type" gorm:"type:varchar(10);not null;uniqueIndex:idx_mention_source_user"`
+	SourceID    uint      `json:"source_id" gorm:"uniqueIndex:idx_mention_source_user"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_mention_source_user;index"`
+	MentionerID uint      `json:"mentioner_id"`
+	Notified    bool      `json:"notified" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MentionRef is a resolved mention as shown to clients, so they can link
+// "@username" to the user's profile.
+type MentionRef struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Comment moderation modes. Posts can override the site-wide mode; an
+// empty mode on a post means it follows the site setting.
+const (
+	ModerationAuto      = "auto"       // publish every comment
+	ModerationFirstTime = "first_time" // hold comments from users without an approved comment
+	ModerationLinks     = "links"      // hold comments that contain links
+	ModerationAll       = "all"        // hold every comment
+)
+
+func IsValidModerationMode(mode string) bool {
+	switch mode {
+	case ModerationAuto, ModerationFirstTime, ModerationLinks, ModerationAll:
+		return true
+	}
+	return false
+}
+
+// ModerationSetting holds the site-wide moderation mode. There is a single
+// row with ID 1.
+type ModerationSetting struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Mode      string    `json:"mode" gorm:"type:varchar(20);not null;default:auto"`
+	UpdatedBy uint      `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Notification types
+const (
+	NotificationCommentOnPost = "comment_on_post"
+	NotificationCommentReply  = "comment_reply"
+	NotificationNewFollower   = "new_follower"
+	NotificationMention       = "mention"
+	NotificationPostApproved  = "post_approved"
+)
+
+// What a notification is about
+const (
+	NotificationSubjectPost    = "post"
+	NotificationSubjectComment = "comment"
+)
+
+// NotificationTypes lists every type users can turn on or off
+var NotificationTypes = []string{
+	NotificationCommentOnPost, NotificationCommentReply, NotificationNewFollower,
+	NotificationMention, NotificationPostApproved,
+}
+
+func IsValidNotificationType(t string) bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user that something happened that involves them.
+// SubjectType and SubjectID point at what it is about (a post or comment);
+// PostID is set whenever there is a post to link to.
+type Notification struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index:idx_notification_user_read"`
+	ActorID     uint       `json:"actor_id"`
+	Type        string     `json:"type" gorm:"type:varchar(30);not null"`
+	SubjectType string     `json:"subject_type" gorm:"type:varchar(10)"`
+	SubjectID   uint       `json:"subject_id"`
+	PostID      uint       `json:"post_id"`
+	ReadAt      *time.Time `json:"read_at" gorm:"index:idx_notification_user_read"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Actor User `json:"actor" gorm:"foreignKey:ActorID"`
+}
+
+// NotificationPreference turns one notification type on or off for a user.
+// Types without a row are on.
+type NotificationPreference struct {
+	UserID  uint   `json:"-" gorm:"primaryKey"`
+	Type    string `json:"type" gorm:"primaryKey;type:varchar(30)"`
+	Enabled bool   `json:"enabled"`
+}
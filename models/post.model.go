@@ -6,17 +6,92 @@ import (
 	"gorm.io/gorm"
 )
 
+// Post visibility levels. Unlisted posts are reachable by link but left out
+// of listings; password posts need an access cookie from the unlock endpoint.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+	VisibilityPassword = "password"
+)
+
+func IsValidVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate, VisibilityPassword:
+		return true
+	}
+	return false
+}
+
+// Review states of a post in the editorial workflow.
+const (
+	ReviewNone             = ""
+	ReviewPending          = "pending"
+	ReviewChangesRequested = "changes_requested"
+	ReviewApproved         = "approved"
+)
+
 type Blog struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
+	// Version increases on every content update and backs the ETag used
+	// for optimistic concurrency
+	Version uint `json:"version" gorm:"not null;default:1"`
+
 	Title   string `json:"title" gorm:"type:longtext;not null"`
 	Content string `json:"content" gorm:"type:longtext;not null"`
 	UserID  uint   `json:"user_id" gorm:"not null;index"`
 
 	Published bool `json:"published" gorm:"not null;default:false"`
 	Draft     bool `json:"draft" gorm:"not null;default:false"`
-	User      User `json:"user" gorm:"foreignKey:UserID"`
+
+	// PublishedAt is when the post first went live; nil for posts never
+	// published, or published before it was recorded
+	PublishedAt *time.Time `json:"published_at"`
+
+	Visibility   string `json:"visibility" gorm:"type:varchar(20);not null;default:public;index"`
+	PasswordHash string `json:"-"`
+
+	ReviewStatus string     `json:"review_status" gorm:"type:varchar(20);not null;default:'';index"`
+	ReviewedBy   *uint      `json:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+
+	// Comment settings: disabled posts neither show nor accept comments,
+	// closed ones (CommentsCloseAfterDays after publication) stop
+	// accepting new ones, and locked threads are read-only
+	CommentsDisabled       bool       `json:"comments_disabled" gorm:"not null;default:false"`
+	CommentsCloseAfterDays int        `json:"comments_close_after_days" gorm:"not null;default:0"`
+	CommentsLocked         bool       `json:"comments_locked" gorm:"not null;default:false"`
+	CommentsLockedBy       *uint      `json:"comments_locked_by"`
+	CommentsLockedAt       *time.Time `json:"comments_locked_at"`
+
+	// PinnedCommentID is a top-level comment the authors show first
+	PinnedCommentID *uint `json:"pinned_comment_id"`
+
+	// Cover image: CoverImage is the original's URL, CoverRenditions its
+	// resized copies once processed
+	CoverImage      string                    `json:"cover_image"`
+	CoverImageKey   string                    `json:"-"`
+	CoverRenditions map[string]MediaRendition `json:"cover_renditions" gorm:"-"`
+
+	// Overrides the site-wide comment moderation mode when set
+	ModerationMode string `json:"moderation_mode" gorm:"type:varchar(20);not null;default:''"`
+
+	// Hidden by moderators, or automatically once enough readers report it
+	// (HiddenByReports), in which case dismissing the reports unhides it
+	Hidden          bool `json:"hidden" gorm:"not null;default:false;index"`
+	HiddenByReports bool `json:"-" gorm:"not null;default:false"`
+
+	// Users @mentioned in Content, filled in for responses
+	Mentions []MentionRef `json:"mentions" gorm:"-"`
+
+	User User  `json:"user" gorm:"foreignKey:UserID"`
+	Tags []Tag `json:"tags" gorm:"many2many:blog_tags"`
+
+	// Only co-authors are preloaded here; reviewers are listed through the
+	// collaborators endpoint
+	CoAuthors []PostCollaborator `json:"co_authors" gorm:"foreignKey:PostID"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PostStat caches engagement totals and the trending score of a post.
+// Reactions counts the reactions on the post's visible comments.
+// Rows are rebuilt periodically by the trending job, so sorting by them
+// is a cheap indexed read.
+type PostStat struct {
+	PostID        uint      `json:"post_id" gorm:"primaryKey;autoIncrement:false"`
+	Views         uint64    `json:"views" gorm:"not null;default:0;index"`
+	Comments      int64     `json:"comments" gorm:"not null;default:0;index"`
+	Reactions     int64     `json:"reactions" gorm:"not null;default:0;index"`
+	TrendingScore float64   `json:"trending_score" gorm:"not null;default:0;index"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PostViewDaily holds the number of counted views of a post for one day.
+type PostViewDaily struct {
+	ID     uint      `json:"-" gorm:"primaryKey"`
+	PostID uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_post_view_day"`
+	Day    time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_post_view_day"`
+	Views  uint64    `json:"views" gorm:"not null;default:0"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PreviewLink records a signed draft preview token so that it can be
+// listed and revoked. The token itself is never stored.
+type PreviewLink struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"created_at"`
+	PostID    uint       `json:"post_id" gorm:"not null;index"`
+	CreatedBy uint       `json:"created_by" gorm:"not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
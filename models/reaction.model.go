@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Reaction types accepted on comments.
+const (
+	ReactionLike       = "like"
+	ReactionLove       = "love"
+	ReactionInsightful = "insightful"
+	ReactionFunny      = "funny"
+)
+
+func IsValidReaction(t string) bool {
+	switch t {
+	case ReactionLike, ReactionLove, ReactionInsightful, ReactionFunny:
+		return true
+	}
+	return false
+}
+
+// CommentReaction is one user's reaction to a comment; a user has at most
+// one reaction per comment.
+type CommentReaction struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	CommentID uint      `json:"comment_id" gorm:"not null;uniqueIndex:idx_comment_reaction_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_comment_reaction_user"`
+	Type      string    `json:"type" gorm:"type:varchar(20);not null"`
+}
+
+// Comment vote values
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// CommentVote is one user's up- or downvote on a comment; a user has at
+// most one vote per comment.
+type CommentVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	CommentID uint      `json:"comment_id" gorm:"not null;uniqueIndex:idx_comment_vote_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_comment_vote_user"`
+	Value     int       `json:"value" gorm:"not null"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RelatedPost is a precomputed similarity between two published posts,
+// rebuilt periodically by the related-posts job.
+type RelatedPost struct {
+	PostID    uint      `json:"post_id" gorm:"primaryKey;autoIncrement:false"`
+	RelatedID uint      `json:"related_id" gorm:"primaryKey;autoIncrement:false"`
+	Score     float64   `json:"score" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
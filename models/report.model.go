@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// What can be reported
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHate           = "hate"
+	ReportReasonSexual         = "sexual"
+	ReportReasonViolence       = "violence"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonOther          = "other"
+)
+
+func IsValidReportReason(reason string) bool {
+	switch reason {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonHate, ReportReasonSexual,
+		ReportReasonViolence, ReportReasonMisinformation, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// Report triage states
+const (
+	ReportOpen      = "open"
+	ReportDismissed = "dismissed"
+	ReportActioned  = "actioned"
+)
+
+// Moderator resolutions of a report
+const (
+	ReportActionDismiss = "dismiss"
+	ReportActionHide    = "hide"
+	ReportActionSuspend = "suspend"
+)
+
+// Report is one reader flagging a post, comment or user. A reader can
+// report the same target only once.
+type Report struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ReporterID uint   `json:"reporter_id" gorm:"uniqueIndex:idx_report_reporter_target"`
+	TargetType string `json:"target_type" gorm:"type:varchar(10);not null;uniqueIndex:idx_report_reporter_target;index:idx_report_target"`
+	TargetID   uint   `json:"target_id" gorm:"uniqueIndex:idx_report_reporter_target;index:idx_report_target"`
+	Reason     string `json:"reason" gorm:"type:varchar(20);not null"`
+	Note       string `json:"note" gorm:"type:text"`
+	Status     string `json:"status" gorm:"type:varchar(10);not null;default:open;index"`
+
+	Resolution     string     `json:"resolution" gorm:"type:varchar(10)"`
+	ResolutionNote string     `json:"resolution_note" gorm:"type:text"`
+	ResolvedBy     *uint      `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+
+	Reporter  User      `json:"reporter" gorm:"foreignKey:ReporterID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Kinds of review comments. Plain comments come from collaborators, the
+// others are left by the editorial workflow.
+const (
+	ReviewCommentNote             = "comment"
+	ReviewCommentSubmission       = "submission"
+	ReviewCommentApproval         = "approval"
+	ReviewCommentChangesRequested = "changes_requested"
+)
+
+// ReviewComment is a private note on a post, visible only to the author,
+// collaborators and editors. It is kept apart from public Comment rows.
+type ReviewComment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	PostID    uint      `json:"post_id" gorm:"not null;index"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	Kind      string    `json:"kind" gorm:"type:varchar(20);not null;default:comment"`
+	Content   string    `json:"content" gorm:"type:text;not null"`
+	User      User      `json:"user" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// What the spam scorer decided for a submission
+const (
+	SpamAccept = "accept"
+	SpamHold   = "hold"
+	SpamReject = "reject"
+)
+
+// Kinds of submissions that are scored
+const (
+	SpamSubjectComment = "comment"
+	SpamSubjectUser    = "user"
+)
+
+// Moderator verdicts on a scored submission
+const (
+	SpamVerdictSpam = "spam"
+	SpamVerdictHam  = "ham"
+)
+
+// SpamCheck records how a submission was scored and, once a moderator has
+// looked at it, whether it really was spam. Comparing the two is how the
+// heuristic weights get tuned.
+type SpamCheck struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	SubjectType string  `json:"subject_type" gorm:"type:varchar(20);not null;index:idx_spam_subject"`
+	SubjectID   uint    `json:"subject_id" gorm:"index:idx_spam_subject"` // 0 for rejected submissions
+	UserID      uint    `json:"user_id"`
+	IP          string  `json:"ip" gorm:"type:varchar(64)"`
+	Score       float64 `json:"score"`
+	Action      string  `json:"action" gorm:"type:varchar(10);not null"`
+	Signals     string  `json:"signals" gorm:"type:text"` // JSON map of check name to score
+
+	Verdict   string     `json:"verdict" gorm:"type:varchar(10);not null;default:'';index"`
+	VerdictBy *uint      `json:"verdict_by"`
+	VerdictAt *time.Time `json:"verdict_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type Tag struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"type:varchar(50);uniqueIndex;not null"`
+}
@@ -2,10 +2,32 @@ package models
 
 import "gorm.io/gorm"
 
+// Site-wide user roles. Editors review submitted posts, moderators handle
+// held comments; admins can do everything both can.
+const (
+	UserRoleUser      = "user"
+	UserRoleEditor    = "editor"
+	UserRoleModerator = "moderator"
+	UserRoleAdmin     = "admin"
+)
+
+// Account states. Registrations that look like spam are held until a
+// moderator approves them; only active accounts can log in.
+const (
+	UserStatusActive    = "active"
+	UserStatusHeld      = "held"
+	UserStatusSpam      = "spam"
+	UserStatusSuspended = "suspended"
+)
+
 type User struct {
 	gorm.Model
 	Username     string `json:"username" gorm:"unique"`
 	Email        string `json:"email" gorm:"unique"`
 	Password     string `json:"password"`
 	ProfileImage string `json:"profile_image"`
+	// ProfileImageKey is the storage key of an uploaded ProfileImage
+	ProfileImageKey string `json:"-"`
+	Role            string `json:"role" gorm:"type:varchar(20);not null;default:user"`
+	Status          string `json:"status" gorm:"type:varchar(20);not null;default:active;index"`
 }
@@ -11,13 +11,18 @@ func RegisterCommentRoutes(r *gin.Engine) {
 	commentRoutes := r.Group("/comments")
 	// Anyone can view comments
 	commentRoutes.GET("/", controllers.GetAllComments)
-	commentRoutes.GET("/:id", controllers.GetComment)
-	commentRoutes.GET("/count/:post_id", controllers.GetCommentCount)
-	commentRoutes.GET("/post/:post_id", controllers.GetCommentsByPost) // ✅ new
+	commentRoutes.GET("/:id", middlewares.OptionalAuthMiddleware(), controllers.GetComment)
+	commentRoutes.GET("/:id/history", middlewares.OptionalAuthMiddleware(), controllers.GetCommentHistory)
+	commentRoutes.GET("/count/:post_id", middlewares.OptionalAuthMiddleware(), controllers.GetCommentCount)
+	commentRoutes.GET("/post/:post_id", middlewares.OptionalAuthMiddleware(), controllers.GetCommentsByPost) // ✅ new
 	commentRoutes.Use(middlewares.AuthMiddleware())
 	{
 		commentRoutes.POST("/", controllers.CreateComment)
 		commentRoutes.PUT("/:id", controllers.UpdateComment)
 		commentRoutes.DELETE("/:id", controllers.DeleteComment)
+		commentRoutes.PUT("/:id/reactions", controllers.ReactToComment)
+		commentRoutes.DELETE("/:id/reactions", controllers.RemoveCommentReaction)
+		commentRoutes.PUT("/:id/vote", controllers.VoteComment)
+		commentRoutes.DELETE("/:id/vote", controllers.RemoveCommentVote)
 	}
 }
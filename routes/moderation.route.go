@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+	"BlogApp/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterModerationRoutes(r *gin.Engine) {
+	// Moderators only
+	moderation := r.Group("/api/moderation")
+	moderation.Use(middlewares.AuthMiddleware(), middlewares.RequireRole(models.UserRoleModerator))
+	{
+		moderation.GET("/comments", controllers.GetModerationQueue)
+		moderation.POST("/comments/:id/approve", controllers.ApproveComment)
+		moderation.POST("/comments/:id/reject", controllers.RejectComment)
+		moderation.POST("/comments/:id/spam", controllers.MarkCommentSpam)
+		moderation.GET("/users", controllers.GetHeldUsers)
+		moderation.POST("/users/:id/approve", controllers.ApproveUser)
+		moderation.POST("/users/:id/spam", controllers.MarkUserSpam)
+		moderation.POST("/users/:id/reinstate", controllers.ReinstateUser)
+		moderation.GET("/spam/stats", controllers.GetSpamStats)
+		moderation.GET("/reports", controllers.GetReports)
+		moderation.POST("/reports/:id/resolve", controllers.ResolveReport)
+		moderation.GET("/settings", controllers.GetModerationSettings)
+		moderation.PUT("/settings", controllers.UpdateModerationSettings)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterNotificationRoutes(r *gin.Engine) {
+	notifications := r.Group("/api/notifications")
+	notifications.Use(middlewares.AuthMiddleware())
+	{
+		notifications.GET("/", controllers.GetNotifications)
+		notifications.POST("/read-all", controllers.MarkAllNotificationsRead)
+		notifications.POST("/:id/read", controllers.MarkNotificationRead)
+		notifications.GET("/preferences", controllers.GetNotificationPreferences)
+		notifications.PUT("/preferences", controllers.UpdateNotificationPreferences)
+	}
+}
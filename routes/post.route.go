@@ -10,13 +10,51 @@ import (
 func RegisterBlogRoutes(r *gin.Engine) {
 	posts := r.Group("/api")
 	// public routes
-	posts.GET("/getPosts", controllers.GetAllPosts)
-	posts.GET("/singlePost/:id", controllers.GetPostById)
+	posts.GET("/getPosts", middlewares.OptionalAuthMiddleware(), controllers.GetAllPosts)
+	posts.GET("/singlePost/:id", middlewares.OptionalAuthMiddleware(), controllers.GetPostById)
+	posts.GET("/posts/:id/related", middlewares.OptionalAuthMiddleware(), controllers.GetRelatedPosts)
+	posts.POST("/posts/:id/unlock", controllers.UnlockPost)
+	posts.GET("/preview/:token", controllers.GetPostPreview)
 	// protected routes
 	posts.Use(middlewares.AuthMiddleware())
 	{
 		posts.POST("/create", controllers.CreatePost)
 		posts.PUT("/updatePost/:id", controllers.UpdateById)
 		posts.DELETE("/deletePost/:id", controllers.DeleteById)
+		posts.POST("/posts/bulk", controllers.BulkUpdatePosts)
+		posts.GET("/posts/:id/views", controllers.GetPostViews)
+
+		// collaborators and private review comments
+		posts.GET("/posts/:id/collaborators", controllers.GetCollaborators)
+		posts.POST("/posts/:id/collaborators", controllers.InviteCollaborator)
+		posts.DELETE("/posts/:id/collaborators/:user_id", controllers.RemoveCollaborator)
+		posts.GET("/posts/:id/review-comments", controllers.GetReviewComments)
+		posts.POST("/posts/:id/review-comments", controllers.CreateReviewComment)
+		posts.POST("/posts/:id/submit", controllers.SubmitForReview)
+
+		// draft preview links
+		posts.GET("/posts/:id/previews", controllers.GetPreviewLinks)
+		posts.POST("/posts/:id/previews", controllers.CreatePreviewLink)
+		posts.DELETE("/posts/:id/previews/:preview_id", controllers.RevokePreviewLink)
+
+		// per-user working copies
+		posts.GET("/posts/:id/autosave", controllers.GetAutosave)
+		posts.PUT("/posts/:id/autosave", controllers.SaveAutosave)
+		posts.DELETE("/posts/:id/autosave", controllers.DiscardAutosave)
+		posts.POST("/posts/:id/autosave/commit", controllers.CommitAutosave)
+
+		// comment moderation override
+		posts.PUT("/posts/:id/moderation", controllers.UpdatePostModeration)
+
+		// comment settings; moderators can lock any thread
+		posts.PUT("/posts/:id/comment-settings", controllers.UpdateCommentSettings)
+		posts.POST("/posts/:id/lock", controllers.LockComments)
+		posts.DELETE("/posts/:id/lock", controllers.UnlockComments)
+		posts.PUT("/posts/:id/pinned-comment", controllers.PinComment)
+		posts.DELETE("/posts/:id/pinned-comment", controllers.UnpinComment)
+
+		// cover image; resized copies are made in the background
+		posts.PUT("/posts/:id/cover", controllers.UploadPostCover)
+		posts.DELETE("/posts/:id/cover", controllers.DeletePostCover)
 	}
 }
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReportRoutes(r *gin.Engine) {
+	// Any signed-in reader can report content
+	reports := r.Group("/api/reports")
+	reports.Use(middlewares.AuthMiddleware())
+	{
+		reports.POST("/", controllers.CreateReport)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+	"BlogApp/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReviewRoutes(r *gin.Engine) {
+	// Editors only
+	review := r.Group("/api/review")
+	review.Use(middlewares.AuthMiddleware(), middlewares.RequireRole(models.UserRoleEditor))
+	{
+		review.GET("/queue", controllers.GetReviewQueue)
+		review.POST("/:id/approve", controllers.ApprovePost)
+		review.POST("/:id/request-changes", controllers.RequestChanges)
+	}
+}
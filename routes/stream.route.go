@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterStreamRoutes(r *gin.Engine) {
+	// Server-Sent Events; EventSource can't set headers, so it sends a
+	// ticket from POST /ticket as ?ticket= instead of the login token
+	stream := r.Group("/api/stream")
+	{
+		stream.POST("/ticket", middlewares.AuthMiddleware(), controllers.CreateStreamTicket)
+		stream.GET("/posts/:id/comments", middlewares.OptionalStreamAuthMiddleware(), controllers.StreamPostComments)
+		stream.GET("/notifications", middlewares.StreamAuthMiddleware(), controllers.StreamNotifications)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"BlogApp/controllers"
+	"BlogApp/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTrashRoutes(r *gin.Engine) {
+	// Every trash route works on the current user's own items
+	trash := r.Group("/api/trash")
+	trash.Use(middlewares.AuthMiddleware())
+	{
+		trash.GET("/", controllers.GetTrash)
+		trash.POST("/posts/:id/restore", controllers.RestorePost)
+		trash.DELETE("/posts/:id", controllers.PurgePost)
+		trash.POST("/comments/:id/restore", controllers.RestoreComment)
+		trash.DELETE("/comments/:id", controllers.PurgeComment)
+	}
+}
@@ -18,5 +18,7 @@ func RegisterUserRoutes(r *gin.Engine) {
 	{
 		userGroup.GET("/profile", controllers.GetProfile)
 		userGroup.PUT("/profile", controllers.UpdateProfile)
+		userGroup.POST("/:id/follow", controllers.FollowUser)
+		userGroup.DELETE("/:id/follow", controllers.UnfollowUser)
 	}
 }
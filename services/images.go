@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// renditionSpec is one resized copy: images are scaled down to fit in a
+// Size×Size box.
+type renditionSpec struct {
+	Name string
+	Size int
+}
+
+// imageProfile says how the uploads of one kind (the first segment of
+// their key) are processed.
+type imageProfile struct {
+	Square     bool
+	Renditions []renditionSpec // largest first; each is scaled from the previous
+}
+
+var imageProfiles = map[string]imageProfile{
+	"avatars": {Square: true, Renditions: []renditionSpec{{"lg", 256}, {"md", 128}, {"sm", 64}}},
+	"posts":   {Renditions: []renditionSpec{{"lg", 1600}, {"md", 800}, {"sm", 400}}},
+}
+
+// ImagePipeline turns uploaded originals into resized renditions on a pool
+// of background workers, so uploads return as soon as the original is
+// stored.
+type ImagePipeline struct {
+	db        *gorm.DB
+	store     Storage
+	jobs      chan string
+	maxPixels int
+	quality   int
+
+	mu       sync.Mutex
+	queued   map[string]bool
+	failures map[string]bool // sources that cannot be processed; not retried
+}
+
+// Images is the process-wide pipeline. It stays nil until
+// StartImagePipeline is called, and a nil pipeline ignores new images.
+var Images *ImagePipeline
+
+// StartImagePipeline starts IMAGE_WORKERS workers (default 2) on the media
+// store and a job that picks up images still lacking renditions every
+// IMAGE_RESCAN_INTERVAL, such as those queued before a restart.
+func StartImagePipeline() {
+	Images = NewImagePipeline(config.DB, Media, config.GetEnvInt("IMAGE_QUEUE_SIZE", 100))
+	Images.maxPixels = config.GetEnvInt("IMAGE_MAX_PIXELS", 40_000_000)
+	Images.quality = config.GetEnvInt("IMAGE_JPEG_QUALITY", 82)
+	for i := 0; i < config.GetEnvInt("IMAGE_WORKERS", 2); i++ {
+		go Images.work()
+	}
+	go runEvery(config.GetEnvDuration("IMAGE_RESCAN_INTERVAL", 10*time.Minute), "image renditions", Images.EnqueueMissing)
+}
+
+func NewImagePipeline(db *gorm.DB, store Storage, queueSize int) *ImagePipeline {
+	return &ImagePipeline{
+		db:        db,
+		store:     store,
+		jobs:      make(chan string, queueSize),
+		maxPixels: 40_000_000,
+		quality:   82,
+		queued:    make(map[string]bool),
+		failures:  make(map[string]bool),
+	}
+}
+
+// Enqueue schedules renditions for a stored original. It never blocks:
+// when the queue is full the image is left for the next rescan.
+func (p *ImagePipeline) Enqueue(sourceKey string) {
+	if p == nil || sourceKey == "" {
+		return
+	}
+	if _, ok := imageProfiles[keyKind(sourceKey)]; !ok {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queued[sourceKey] || p.failures[sourceKey] {
+		return
+	}
+	select {
+	case p.jobs <- sourceKey:
+		p.queued[sourceKey] = true
+	default:
+		log.Println("Image queue full, deferring", sourceKey)
+	}
+}
+
+// EnqueueMissing queues every avatar and post image that has no renditions
+// yet.
+func (p *ImagePipeline) EnqueueMissing() error {
+	missing := func(table, column string) ([]string, error) {
+		var keys []string
+		err := p.db.Table(table).
+			Where(column+" <> ''").
+			Where("NOT EXISTS (SELECT 1 FROM media_renditions WHERE media_renditions.source_key = "+table+"."+column+")").
+			Distinct().Limit(cap(p.jobs)).
+			Pluck(column, &keys).Error
+		return keys, err
+	}
+
+	users, err := missing("users", "profile_image_key")
+	if err != nil {
+		return err
+	}
+	posts, err := missing("blogs", "cover_image_key")
+	if err != nil {
+		return err
+	}
+	for _, key := range append(users, posts...) {
+		p.Enqueue(key)
+	}
+	return nil
+}
+
+func (p *ImagePipeline) work() {
+	for key := range p.jobs {
+		err := p.Process(context.Background(), key)
+
+		p.mu.Lock()
+		delete(p.queued, key)
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			p.failures[key] = true
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			log.Printf("Failed to process image %s: %v", key, err)
+		}
+	}
+}
+
+// Process decodes an original, turns it upright, crops avatars to a
+// square and stores each rendition. Re-encoding leaves all metadata
+// behind. Renditions are JPEG, or PNG for images with transparency,
+// whatever the format of the original: there is no WebP encoder to write
+// WebP renditions with.
+func (p *ImagePipeline) Process(ctx context.Context, sourceKey string) error {
+	profile, ok := imageProfiles[keyKind(sourceKey)]
+	if !ok {
+		return fmt.Errorf("no image profile for %s", sourceKey)
+	}
+
+	rc, err := p.store.Get(ctx, sourceKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	// Check the dimensions before decoding so a tiny file cannot expand
+	// into gigabytes of pixels
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if cfg.Width*cfg.Height > p.maxPixels {
+		return fmt.Errorf("image is %dx%d, over the %d pixel limit", cfg.Width, cfg.Height, p.maxPixels)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	img := toNRGBA(decoded)
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	if profile.Square {
+		img = cropSquare(img)
+	}
+
+	ext, contentType := ".jpg", "image/jpeg"
+	if !img.Opaque() {
+		ext, contentType = ".png", "image/png"
+	}
+
+	base := path.Join("renditions", strings.TrimSuffix(sourceKey, path.Ext(sourceKey)))
+	rows := make([]models.MediaRendition, 0, len(profile.Renditions))
+	for _, spec := range profile.Renditions {
+		w, h := fitSize(img.Rect.Dx(), img.Rect.Dy(), spec.Size)
+		img = resizeImage(img, w, h)
+
+		var buf bytes.Buffer
+		if contentType == "image/png" {
+			err = png.Encode(&buf, img)
+		} else {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality})
+		}
+		if err != nil {
+			return err
+		}
+
+		key := base + "/" + spec.Name + ext
+		if err := p.store.Put(ctx, key, buf.Bytes(), contentType); err != nil {
+			return err
+		}
+		rows = append(rows, models.MediaRendition{
+			SourceKey:   sourceKey,
+			Name:        spec.Name,
+			Key:         key,
+			Width:       w,
+			Height:      h,
+			ContentType: contentType,
+		})
+	}
+
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_key"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"object_key", "width", "height", "content_type"}),
+	}).Create(&rows).Error
+}
+
+// keyKind is the first segment of a storage key, e.g. "avatars"
+func keyKind(key string) string {
+	kind, _, _ := strings.Cut(key, "/")
+	return kind
+}
+
+// LoadRenditions returns the renditions of each source key, by name, with
+// their public URLs. Images still being processed have none.
+func LoadRenditions(db *gorm.DB, sourceKeys []string) (map[string]map[string]models.MediaRendition, error) {
+	result := make(map[string]map[string]models.MediaRendition)
+	var keys []string
+	for _, key := range sourceKeys {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	var rows []models.MediaRendition
+	if err := db.Where("source_key IN ?", keys).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		row.URL = Media.URL(row.Key)
+		if result[row.SourceKey] == nil {
+			result[row.SourceKey] = make(map[string]models.MediaRendition)
+		}
+		result[row.SourceKey][row.Name] = row
+	}
+	return result, nil
+}
+
+// DeleteRenditions removes the renditions of an original that is being
+// deleted.
+func DeleteRenditions(db *gorm.DB, sourceKey string) error {
+	var rows []models.MediaRendition
+	if err := db.Where("source_key = ?", sourceKey).Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := Media.Delete(context.Background(), row.Key); err != nil {
+			return err
+		}
+	}
+	return db.Where("source_key = ?", sourceKey).Delete(&models.MediaRendition{}).Error
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// memStorage keeps objects in memory
+type memStorage struct {
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string][]byte), types: make(map[string]string)}
+}
+
+func (s *memStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.objects[key] = data
+	s.types[key] = contentType
+	return nil
+}
+
+func (s *memStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStorage) URL(key string) string { return "/uploads/" + key }
+
+// dryRunDB builds statements without a database behind it, so Process can
+// run up to and including its rendition upsert.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+var (
+	red  = color.NRGBA{255, 0, 0, 255}
+	blue = color.NRGBA{0, 0, 255, 255}
+)
+
+func TestFitSize(t *testing.T) {
+	for _, tt := range []struct {
+		w, h, size   int
+		wantW, wantH int
+	}{
+		{100, 50, 400, 100, 50},
+		{2000, 1000, 800, 800, 400},
+		{1000, 2000, 800, 400, 800},
+		{500, 500, 128, 128, 128},
+		{5000, 1, 100, 100, 1},
+	} {
+		if w, h := fitSize(tt.w, tt.h, tt.size); w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitSize(%d, %d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.size, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// A 2x1 image, red on the left and blue on the right
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 0, blue)
+
+	for _, tt := range []struct {
+		orientation int
+		want        [][]color.NRGBA // rows, top to bottom
+	}{
+		{1, [][]color.NRGBA{{red, blue}}},
+		{2, [][]color.NRGBA{{blue, red}}},
+		{3, [][]color.NRGBA{{blue, red}}},
+		{4, [][]color.NRGBA{{red, blue}}},
+		{5, [][]color.NRGBA{{red}, {blue}}},
+		{6, [][]color.NRGBA{{red}, {blue}}},
+		{7, [][]color.NRGBA{{blue}, {red}}},
+		{8, [][]color.NRGBA{{blue}, {red}}},
+	} {
+		got := applyOrientation(src, tt.orientation)
+		if got.Rect.Dx() != len(tt.want[0]) || got.Rect.Dy() != len(tt.want) {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, got.Rect.Dx(), got.Rect.Dy(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if c := got.NRGBAAt(x, y); c != want {
+					t.Errorf("orientation %d: pixel (%d, %d) = %v, want %v", tt.orientation, x, y, c, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCropSquare(t *testing.T) {
+	// A 6x2 image whose middle two columns are blue
+	src := image.NewNRGBA(image.Rect(0, 0, 6, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 6; x++ {
+			c := red
+			if x == 2 || x == 3 {
+				c = blue
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+
+	got := cropSquare(src)
+	if got.Rect != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("cropped to %v, want 2x2 at the origin", got.Rect)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if c := got.NRGBAAt(x, y); c != blue {
+				t.Errorf("pixel (%d, %d) = %v, want the center of the image", x, y, c)
+			}
+		}
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	// Left half red, right half blue
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := red
+			if x >= 2 {
+				c = blue
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+	got := resizeImage(src, 2, 1)
+	if got.NRGBAAt(0, 0) != red || got.NRGBAAt(1, 0) != blue {
+		t.Errorf("resized pixels = %v %v, want red and blue", got.NRGBAAt(0, 0), got.NRGBAAt(1, 0))
+	}
+
+	// A transparent pixel must not darken its opaque neighbour
+	half := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	half.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 255})
+	half.SetNRGBA(1, 0, color.NRGBA{0, 0, 0, 0})
+	if c := resizeImage(half, 1, 1).NRGBAAt(0, 0); c.R != 255 || c.G != 255 || c.B != 255 || c.A != 127 {
+		t.Errorf("resized pixel = %v, want half-transparent white", c)
+	}
+}
+
+func encodeTestJPEG(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	exif := jpegSegment(0xE1, exifWithOrientation(orientation))
+	return append(append(append([]byte{}, data[:2]...), exif...), data[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	store := newMemStorage()
+	p := NewImagePipeline(dryRunDB(t), store, 1)
+	ctx := context.Background()
+
+	// A landscape photo taken with the camera turned, plus a transparent
+	// WebP and an off-center avatar
+	store.Put(ctx, "posts/ab/cd/photo.jpg", encodeTestJPEG(t, 2000, 1000, 6), "image/jpeg")
+	store.Put(ctx, "posts/ab/cd/clear.webp", []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\r\x00\x00\x00/\x00\x00\x00\x10\a\x10\x11\x11\x88\x88\xfe\a\x00"), "image/webp")
+	store.Put(ctx, "avatars/ab/cd/me.jpg", encodeTestJPEG(t, 300, 200, 1), "image/jpeg")
+
+	for _, key := range []string{"posts/ab/cd/photo.jpg", "posts/ab/cd/clear.webp", "avatars/ab/cd/me.jpg"} {
+		if err := p.Process(ctx, key); err != nil {
+			t.Fatalf("Process(%s): %v", key, err)
+		}
+	}
+
+	for key, want := range map[string]image.Point{
+		"renditions/posts/ab/cd/photo/lg.jpg": {800, 1600},
+		"renditions/posts/ab/cd/photo/md.jpg": {400, 800},
+		"renditions/posts/ab/cd/photo/sm.jpg": {200, 400},
+		"renditions/posts/ab/cd/clear/lg.png": {1, 1},
+		"renditions/posts/ab/cd/clear/sm.png": {1, 1},
+		"renditions/avatars/ab/cd/me/lg.jpg":  {200, 200},
+		"renditions/avatars/ab/cd/me/sm.jpg":  {64, 64},
+	} {
+		data, ok := store.objects[key]
+		if !ok {
+			t.Errorf("%s was not stored", key)
+			continue
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s does not decode: %v", key, err)
+			continue
+		}
+		if got := (image.Point{cfg.Width, cfg.Height}); got != want {
+			t.Errorf("%s is %v, want %v", key, got, want)
+		}
+		if want := "image/" + format; store.types[key] != want {
+			t.Errorf("%s was stored as %s, want %s", key, store.types[key], want)
+		}
+		if bytes.Contains(data, []byte("Exif")) {
+			t.Errorf("%s kept the EXIF block", key)
+		}
+	}
+}
+
+func TestProcessRefusesHugeImages(t *testing.T) {
+	store := newMemStorage()
+	p := NewImagePipeline(dryRunDB(t), store, 1)
+	p.maxPixels = 1000
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 100, 100)))
+	store.Put(ctx, "avatars/ab/cd/big.png", buf.Bytes(), "image/png")
+
+	err := p.Process(ctx, "avatars/ab/cd/big.png")
+	if err == nil || !strings.Contains(err.Error(), "pixel limit") {
+		t.Errorf("err = %v, want the pixel limit error", err)
+	}
+	for key := range store.objects {
+		if strings.HasPrefix(key, "renditions/") {
+			t.Errorf("%s was stored for a refused image", key)
+		}
+	}
+}
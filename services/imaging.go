@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+)
+
+var errMalformedImage = errors.New("malformed image")
+
+// StripMetadata removes EXIF, XMP, IPTC and comment data from a JPEG, PNG,
+// GIF or WebP image without re-encoding it, so uploads do not leak camera
+// details or GPS positions. A JPEG's EXIF orientation is kept, as a minimal
+// EXIF block, because the image renders sideways without it. Other types
+// are refused.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEGMetadata(data)
+	case "image/png":
+		return stripPNGMetadata(data)
+	case "image/gif":
+		return stripGIFMetadata(data)
+	case "image/webp":
+		return stripWebPMetadata(data)
+	}
+	return nil, errMalformedImage
+}
+
+// stripJPEGMetadata drops every APPn segment except JFIF/ICC profiles and
+// all comments, up to the start of the image data.
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errMalformedImage
+	}
+	orientation := jpegOrientation(data)
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	if orientation > 1 {
+		out.Write(orientationEXIF(orientation))
+	}
+
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, errMalformedImage
+		}
+		marker := data[i+1]
+		if marker == 0xFF { // fill byte
+			i++
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errMalformedImage
+		}
+
+		keep := true
+		switch {
+		case marker == 0xE0, marker == 0xE2: // JFIF, ICC profile
+		case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE: // EXIF/XMP/IPTC..., comment
+			keep = false
+		}
+		if keep {
+			out.Write(data[i:end])
+		}
+		if marker == 0xDA { // start of scan: the rest is image data
+			out.Write(data[end:])
+			return out.Bytes(), nil
+		}
+		i = end
+	}
+}
+
+// orientationEXIF builds an APP1 segment holding only the orientation tag
+func orientationEXIF(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big-endian header, IFD0 at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, // Orientation, SHORT, 1 value
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegOrientation reads the EXIF orientation (1-8) of a JPEG, returning 1
+// when there is none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if marker == 0xDA || length < 2 || end > len(data) {
+			break
+		}
+		if segment := data[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// pngDroppedChunks carry text or EXIF metadata
+var pngDroppedChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.WriteString(signature)
+	for i := len(signature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, errMalformedImage
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformedImage
+		}
+		chunk := data[i:end]
+		kind := string(chunk[4:8])
+		if crc32.ChecksumIEEE(chunk[4:8+length]) != binary.BigEndian.Uint32(chunk[8+length:]) {
+			return nil, errMalformedImage
+		}
+		if !pngDroppedChunks[kind] {
+			out.Write(chunk)
+		}
+		if kind == "IEND" {
+			break
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// gifKeptApplications are the application extensions browsers act on: the
+// animation loop count
+var gifKeptApplications = map[string]bool{"NETSCAPE2.0": true, "ANIMEXTS1.0": true}
+
+// stripGIFMetadata drops comment extensions and application extensions
+// (which carry XMP among others) other than the loop count.
+func stripGIFMetadata(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, errMalformedImage
+	}
+	i := 13
+	if flags := data[10]; flags&0x80 != 0 { // global color table
+		i += 3 << (flags&0x07 + 1)
+	}
+	if i > len(data) {
+		return nil, errMalformedImage
+	}
+
+	// skipSubBlocks returns the offset just past the sub-blocks at i
+	skipSubBlocks := func(i int) (int, error) {
+		for i < len(data) {
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				return i, nil
+			}
+		}
+		return 0, errMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:i])
+	for i < len(data) {
+		start := i
+		switch data[i] {
+		case 0x21: // extension
+			if i+2 > len(data) {
+				return nil, errMalformedImage
+			}
+			end, err := skipSubBlocks(i + 2)
+			if err != nil {
+				return nil, err
+			}
+			keep := true
+			switch data[i+1] {
+			case 0xFE: // comment
+				keep = false
+			case 0xFF: // application
+				keep = i+14 <= len(data) && data[i+2] == 11 && gifKeptApplications[string(data[i+3:i+14])]
+			}
+			if keep {
+				out.Write(data[start:end])
+			}
+			i = end
+		case 0x2C: // image descriptor, then the image data
+			if i+11 > len(data) {
+				return nil, errMalformedImage
+			}
+			i += 10
+			if flags := data[i-1]; flags&0x80 != 0 { // local color table
+				i += 3 << (flags&0x07 + 1)
+			}
+			i++ // LZW minimum code size
+			if i > len(data) {
+				return nil, errMalformedImage
+			}
+			end, err := skipSubBlocks(i)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data[start:end])
+			i = end
+		case 0x3B: // trailer
+			out.WriteByte(0x3B)
+			return out.Bytes(), nil
+		default:
+			return nil, errMalformedImage
+		}
+	}
+	return nil, errMalformedImage
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP file, clears
+// the VP8X flags announcing them and fixes up the RIFF size.
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedImage
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if size < 4 || size > len(data)-8 {
+		return nil, errMalformedImage
+	}
+	data = data[:8+size]
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errMalformedImage
+		}
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + length + length&1 // chunks are padded to an even size
+		if length < 0 || end > len(data) {
+			return nil, errMalformedImage
+		}
+		switch kind := string(data[i : i+4]); kind {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			if length < 1 {
+				return nil, errMalformedImage
+			}
+			chunk := bytes.Clone(data[i:end])
+			chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present
+			out.Write(chunk)
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, nil
+}
+
+// toNRGBA copies img into a fresh NRGBA image with its origin at 0,0
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// applyOrientation turns an image the way its EXIF orientation says it
+// should be displayed.
+func applyOrientation(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-dx, dy
+			case 3: // upside down
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // mirrored upside down
+				sx, sy = dx, h-1-dy
+			case 5: // transposed
+				sx, sy = dy, dx
+			case 6: // rotate 90° clockwise
+				sx, sy = dy, h-1-dx
+			case 7: // transversed
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // rotate 90° counter-clockwise
+				sx, sy = w-1-dy, dx
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// cropSquare keeps the largest centered square of the image
+func cropSquare(src *image.NRGBA) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if w == h {
+		return src
+	}
+	size := min(w, h)
+	x, y := (w-size)/2, (h-size)/2
+	return toNRGBA(src.SubImage(image.Rect(x, y, x+size, y+size)))
+}
+
+// fitSize scales w×h down to fit within size×size, keeping the aspect
+// ratio. Images are never scaled up.
+func fitSize(w, h, size int) (int, int) {
+	if w <= size && h <= size {
+		return w, h
+	}
+	if w >= h {
+		return size, max(1, h*size/w)
+	}
+	return max(1, w*size/h), size
+}
+
+// resizeImage scales src down to w×h by averaging the source pixels that
+// fall in each destination pixel (a box filter), weighting colors by alpha
+// so transparent pixels do not darken edges.
+func resizeImage(src *image.NRGBA, w, h int) *image.NRGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if w == sw && h == sh {
+		return src
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for dy := 0; dy < h; dy++ {
+		y0 := dy * sh / h
+		y1 := max(y0+1, (dy+1)*sh/h)
+		for dx := 0; dx < w; dx++ {
+			x0 := dx * sw / w
+			x1 := max(x0+1, (dx+1)*sw/w)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):]
+				for i := 0; i < (x1-x0)*4; i += 4 {
+					pa := uint64(row[i+3])
+					r += uint64(row[i]) * pa
+					g += uint64(row[i+1]) * pa
+					b += uint64(row[i+2]) * pa
+					a += pa
+					n++
+				}
+			}
+
+			p := dst.Pix[dst.PixOffset(dx, dy):]
+			if a > 0 {
+				p[0] = uint8(r / a)
+				p[1] = uint8(g / a)
+				p[2] = uint8(b / a)
+			}
+			p[3] = uint8(a / n)
+		}
+	}
+	return dst
+}
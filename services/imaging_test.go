@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// exifWithOrientation is a little-endian EXIF block holding an orientation
+// and a camera make
+func exifWithOrientation(orientation int) []byte {
+	tiff := []byte{
+		'I', 'I', 42, 0, 8, 0, 0, 0,
+		2, 0,
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0, // Orientation
+		0x0F, 0x01, 2, 0, 6, 0, 0, 0, 38, 0, 0, 0, // Make, at offset 38
+		0, 0, 0, 0,
+		'C', 'a', 'n', 'o', 'n', 0,
+	}
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	data := testJPEG(t)
+	var tainted []byte
+	tainted = append(tainted, data[:2]...)
+	tainted = append(tainted, jpegSegment(0xE1, exifWithOrientation(6))...)
+	tainted = append(tainted, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<gps/>"))...)
+	tainted = append(tainted, jpegSegment(0xED, []byte("Photoshop 3.0\x00iptc"))...)
+	tainted = append(tainted, jpegSegment(0xFE, []byte("a comment"))...)
+	tainted = append(tainted, data[2:]...)
+
+	stripped, err := stripJPEGMetadata(tainted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"Canon", "<gps/>", "iptc", "a comment"} {
+		if bytes.Contains(stripped, []byte(leak)) {
+			t.Errorf("%q was not removed", leak)
+		}
+	}
+	if o := jpegOrientation(stripped); o != 6 {
+		t.Errorf("orientation = %d, want 6", o)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("stripped JPEG does not decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 4 {
+		t.Errorf("stripped JPEG is %dx%d, want 8x4", b.Dx(), b.Dy())
+	}
+}
+
+func TestStripJPEGMetadataWithoutOrientation(t *testing.T) {
+	data := testJPEG(t)
+	tainted := append(append(append([]byte{}, data[:2]...), jpegSegment(0xE1, exifWithOrientation(1))...), data[2:]...)
+
+	stripped, err := stripJPEGMetadata(tainted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stripped, []byte("Exif")) {
+		t.Error("an EXIF block was kept for the default orientation")
+	}
+}
+
+func TestStripJPEGMetadataMalformed(t *testing.T) {
+	data := testJPEG(t)
+	for name, bad := range map[string][]byte{
+		"not a JPEG": []byte("GIF89a"),
+		"truncated":  data[:20],
+		"bad length": append(append([]byte{0xFF, 0xD8}, 0xFF, 0xE1, 0xFF, 0xFF), data[2:]...),
+	} {
+		if _, err := stripJPEGMetadata(bad); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], kind)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	data := testPNG(t)
+	afterIHDR := 8 + 12 + 13
+	var tainted []byte
+	tainted = append(tainted, data[:afterIHDR]...)
+	tainted = append(tainted, pngChunk("tEXt", []byte("Author\x00someone"))...)
+	tainted = append(tainted, pngChunk("eXIf", exifWithOrientation(1)[6:])...)
+	tainted = append(tainted, pngChunk("tIME", []byte{7, 0xE8, 1, 2, 3, 4, 5})...)
+	tainted = append(tainted, data[afterIHDR:]...)
+
+	stripped, err := stripPNGMetadata(tainted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stripped, data) {
+		t.Error("stripped PNG differs from the original without metadata")
+	}
+}
+
+func TestStripPNGMetadataMalformed(t *testing.T) {
+	data := testPNG(t)
+	corrupt := bytes.Clone(data)
+	corrupt[len(corrupt)-20] ^= 0xFF // inside the image data, breaking its CRC
+	for name, bad := range map[string][]byte{
+		"not a PNG": []byte("GIF89a"),
+		"truncated": data[:len(data)-6],
+		"bad CRC":   corrupt,
+	} {
+		if _, err := stripPNGMetadata(bad); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func testGIF(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	img.SetColorIndex(1, 1, 1)
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{img, img}, Delay: []int{0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStripGIFMetadata(t *testing.T) {
+	data := testGIF(t)
+
+	// Put a comment and an XMP application extension before the first image
+	at := bytes.IndexByte(data, 0x2C)
+	comment := append([]byte{0x21, 0xFE, 6}, "secret"...)
+	comment = append(comment, 0)
+	xmp := append([]byte{0x21, 0xFF, 11}, "XMP DataXMP"...)
+	xmp = append(xmp, 4, 'g', 'p', 's', '!', 0)
+	tainted := append(append(append(append([]byte{}, data[:at]...), comment...), xmp...), data[at:]...)
+
+	stripped, err := stripGIFMetadata(tainted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stripped, []byte("secret")) || bytes.Contains(stripped, []byte("XMP Data")) {
+		t.Error("metadata was not removed")
+	}
+	if !bytes.Contains(stripped, []byte("NETSCAPE2.0")) {
+		t.Error("loop count was removed")
+	}
+	if _, err := gif.DecodeAll(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped GIF does not decode: %v", err)
+	}
+}
+
+func TestStripGIFMetadataMalformed(t *testing.T) {
+	data := testGIF(t)
+	if _, err := stripGIFMetadata(data[:len(data)-5]); err == nil {
+		t.Error("truncated GIF was accepted")
+	}
+}
+
+func webpChunk(kind string, payload []byte) []byte {
+	chunk := append([]byte(kind), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(payload)))
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04 | 0x10 // EXIF, XMP, alpha
+	body := append([]byte("WEBP"), webpChunk("VP8X", vp8x)...)
+	body = append(body, webpChunk("VP8L", []byte{0x2F, 1, 2, 3, 4})...)
+	body = append(body, webpChunk("EXIF", []byte("gps data"))...)
+	body = append(body, webpChunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(body)))
+
+	stripped, err := stripWebPMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stripped, []byte("gps data")) || bytes.Contains(stripped, []byte("xmpmeta")) {
+		t.Error("metadata was not removed")
+	}
+	if size := binary.LittleEndian.Uint32(stripped[4:]); int(size) != len(stripped)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(stripped)-8)
+	}
+	if flags := stripped[20]; flags != 0x10 {
+		t.Errorf("VP8X flags = %#x, want 0x10", flags)
+	}
+	if !bytes.Contains(stripped, []byte("VP8L")) {
+		t.Error("image data was removed")
+	}
+}
+
+func TestStripMetadataRefusesUnknownTypes(t *testing.T) {
+	if _, err := StripMetadata("image/bmp", []byte("BM")); err == nil {
+		t.Error("unknown type was accepted")
+	}
+}
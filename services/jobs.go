@@ -0,0 +1,18 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// runEvery calls fn immediately and then on every tick, logging failures.
+func runEvery(interval time.Duration, name string, fn func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(); err != nil {
+			log.Printf("Background job %s failed: %v", name, err)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+)
+
+// maxMentions caps how many users one post or comment can mention, so a
+// single submission can't notify the whole site.
+const maxMentions = 20
+
+// mentionPattern matches @username where the @ is not part of a word or
+// an email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_]{1,50})`)
+
+// ParseMentions returns the distinct usernames @mentioned in text, in the
+// order they first appear.
+func ParseMentions(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		key := strings.ToLower(match[1])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, match[1])
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}
+
+// SyncMentions makes the stored mentions of a post or comment match its
+// current text: mentions that were edited out are removed and new ones are
+// added. When notify is set, every mentioned user who hasn't been told yet
+// gets a notification; drafts pass false and notify once published.
+func SyncMentions(tx *gorm.DB, sourceType string, sourceID, authorID, postID uint, text string, notify bool) error {
+	var users []models.User
+	if names := ParseMentions(text); len(names) > 0 {
+		if err := tx.Select("id", "username").
+			Where("username IN ? AND status = ?", names, models.UserStatusActive).
+			Find(&users).Error; err != nil {
+			return err
+		}
+	}
+
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		if user.ID != authorID {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	remove := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID)
+	if len(ids) > 0 {
+		remove = remove.Where("user_id NOT IN ?", ids)
+	}
+	if err := remove.Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+
+	var existing []uint
+	if err := tx.Model(&models.Mention{}).
+		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Pluck("user_id", &existing).Error; err != nil {
+		return err
+	}
+	have := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		have[id] = true
+	}
+	for _, id := range ids {
+		if have[id] {
+			continue
+		}
+		if err := tx.Create(&models.Mention{
+			SourceType:  sourceType,
+			SourceID:    sourceID,
+			UserID:      id,
+			MentionerID: authorID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if !notify {
+		return nil
+	}
+	return NotifyPendingMentions(tx, sourceType, sourceID, postID)
+}
+
+// NotifyPendingMentions notifies the users mentioned in a post or comment
+// who haven't been notified yet.
+func NotifyPendingMentions(tx *gorm.DB, sourceType string, sourceID, postID uint) error {
+	var pending []models.Mention
+	if err := tx.Where("source_type = ? AND source_id = ? AND notified = ?", sourceType, sourceID, false).
+		Find(&pending).Error; err != nil {
+		return err
+	}
+	for _, mention := range pending {
+		if err := Notify(tx, models.Notification{
+			UserID:      mention.UserID,
+			ActorID:     mention.MentionerID,
+			Type:        models.NotificationMention,
+			SubjectType: sourceType,
+			SubjectID:   sourceID,
+			PostID:      postID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Model(&mention).Update("notified", true).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadMentions returns the resolved mentions of the given posts or
+// comments, keyed by source ID.
+func LoadMentions(db *gorm.DB, sourceType string, sourceIDs []uint) (map[uint][]models.MentionRef, error) {
+	refs := make(map[uint][]models.MentionRef)
+	if len(sourceIDs) == 0 {
+		return refs, nil
+	}
+
+	var rows []struct {
+		SourceID uint
+		UserID   uint
+		Username string
+	}
+	if err := db.Table("mentions").
+		Select("mentions.source_id, mentions.user_id, users.username").
+		Joins("JOIN users ON users.id = mentions.user_id AND users.deleted_at IS NULL").
+		Where("mentions.source_type = ? AND mentions.source_id IN ?", sourceType, sourceIDs).
+		Order("mentions.id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		refs[row.SourceID] = append(refs[row.SourceID], models.MentionRef{UserID: row.UserID, Username: row.Username})
+	}
+	return refs, nil
+}
+
+// DeleteMentions removes every mention stored for a post or comment.
+func DeleteMentions(tx *gorm.DB, sourceType string, sourceID uint) error {
+	return tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&models.Mention{}).Error
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"no mentions here", nil},
+		{"@alice hi", []string{"alice"}},
+		{"thanks @alice and @bob_2!", []string{"alice", "bob_2"}},
+		{"(@alice) @bob, @carol.", []string{"alice", "bob", "carol"}},
+		{"@Alice and @alice again", []string{"Alice"}},
+		{"mail me at alice@example.com", nil},
+		{"word@bob is not a mention", nil},
+		{"@@bob is not either", nil},
+		{".@bob neither", nil},
+		{"line one\n@bob", []string{"bob"}},
+		{"@ lonely at sign", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMentions(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseMentionsCapped(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < maxMentions+5; i++ {
+		fmt.Fprintf(&text, "@user%d ", i)
+	}
+	if got := ParseMentions(text.String()); len(got) != maxMentions {
+		t.Errorf("got %d mentions, want %d", len(got), maxMentions)
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+)
+
+// Notify stores a notification for n.UserID unless the user turned that
+// type off. Users are never notified of their own actions. Within a
+// Transaction, it is only pushed to the user's stream once committed.
+func Notify(db *gorm.DB, n models.Notification) error {
+	if n.UserID == 0 || n.UserID == n.ActorID {
+		return nil
+	}
+
+	var disabled int64
+	if err := db.Model(&models.NotificationPreference{}).
+		Where("user_id = ? AND type = ? AND enabled = ?", n.UserID, n.Type, false).
+		Count(&disabled).Error; err != nil {
+		return err
+	}
+	if disabled > 0 {
+		return nil
+	}
+	if err := db.Create(&n).Error; err != nil {
+		return err
+	}
+
+	AfterCommit(db, func() {
+		Realtime.Publish(UserNotificationsTopic(n.UserID), "notification", n)
+	})
+	return nil
+}
+
+// NotifyNewComment tells the post's author about a new comment and, for a
+// reply, the author of the parent comment. Someone who is both only gets
+// the reply notification. Call it once the comment is public.
+func NotifyNewComment(db *gorm.DB, comment models.Comment) error {
+	var parentAuthor uint
+	if comment.ParentID != nil {
+		var parent models.Comment
+		if err := db.Select("id", "user_id", "is_deleted").First(&parent, *comment.ParentID).Error; err == nil && !parent.IsDeleted {
+			parentAuthor = parent.UserID
+		}
+	}
+	if parentAuthor != 0 {
+		if err := Notify(db, models.Notification{
+			UserID:      parentAuthor,
+			ActorID:     comment.UserID,
+			Type:        models.NotificationCommentReply,
+			SubjectType: models.NotificationSubjectComment,
+			SubjectID:   comment.ID,
+			PostID:      comment.PostID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	var blog models.Blog
+	if err := db.Select("id", "user_id").First(&blog, comment.PostID).Error; err != nil {
+		return err
+	}
+	if blog.UserID == parentAuthor {
+		return nil
+	}
+	return Notify(db, models.Notification{
+		UserID:      blog.UserID,
+		ActorID:     comment.UserID,
+		Type:        models.NotificationCommentOnPost,
+		SubjectType: models.NotificationSubjectComment,
+		SubjectID:   comment.ID,
+		PostID:      comment.PostID,
+	})
+}
+
+// DeleteNotificationsAbout removes notifications pointing at a post or
+// comment that no longer exists.
+func DeleteNotificationsAbout(tx *gorm.DB, subjectType string, subjectID uint) error {
+	query := tx.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID)
+	if subjectType == models.NotificationSubjectPost {
+		query = tx.Where("post_id = ? OR (subject_type = ? AND subject_id = ?)", subjectID, subjectType, subjectID)
+	}
+	return query.Delete(&models.Notification{}).Error
+}
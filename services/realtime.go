@@ -0,0 +1,195 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+
+	"BlogApp/config"
+)
+
+// Broker carries published events between server instances. Every
+// instance's hub subscribes to the broker, so an event published on one
+// instance reaches clients connected to any of them. LocalBroker only
+// serves a single instance; a Redis or NATS broker can replace it.
+type Broker interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(deliver func(topic string, payload []byte)) error
+}
+
+// LocalBroker delivers events to the hubs of this process only.
+type LocalBroker struct {
+	mu       sync.RWMutex
+	handlers []func(topic string, payload []byte)
+}
+
+func (b *LocalBroker) Publish(topic string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, deliver := range b.handlers {
+		deliver(topic, payload)
+	}
+	return nil
+}
+
+func (b *LocalBroker) Subscribe(deliver func(topic string, payload []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, deliver)
+	return nil
+}
+
+// Event is what subscribers receive: a type such as "comment.created" and
+// its JSON data.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Subscription receives the events of one topic. When the client falls
+// too far behind, its buffer overflows and Dropped is closed; the client
+// is expected to reconnect and reload what it missed. Closing is closed
+// when the hub shuts down.
+type Subscription struct {
+	C       <-chan Event
+	Dropped <-chan struct{}
+	Closing <-chan struct{}
+
+	hub     *Hub
+	topic   string
+	ch      chan Event
+	dropped chan struct{}
+	once    sync.Once
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if subs, ok := s.hub.topics[s.topic]; ok {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(s.hub.topics, s.topic)
+		}
+	}
+}
+
+func (s *Subscription) drop() {
+	s.once.Do(func() { close(s.dropped) })
+}
+
+// Hub fans out events to the subscribers of each topic on this instance.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	broker Broker
+	buffer int
+
+	closing  chan struct{}
+	shutdown sync.Once
+}
+
+// Realtime is the process-wide hub. It stays nil until StartRealtime is
+// called, and publishing to a nil hub does nothing.
+var Realtime *Hub
+
+// StartRealtime creates the global hub on a local broker, giving every
+// subscriber a buffer of STREAM_BUFFER events (default 32).
+func StartRealtime() {
+	hub, err := NewHub(&LocalBroker{}, config.GetEnvInt("STREAM_BUFFER", 32))
+	if err != nil {
+		log.Fatal("Failed to start realtime hub:", err)
+	}
+	Realtime = hub
+}
+
+func NewHub(broker Broker, buffer int) (*Hub, error) {
+	h := &Hub{
+		topics:  make(map[string]map[*Subscription]struct{}),
+		broker:  broker,
+		buffer:  buffer,
+		closing: make(chan struct{}),
+	}
+	if err := broker.Subscribe(h.deliver); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Subscribe starts receiving events published to topic.
+func (h *Hub) Subscribe(topic string) *Subscription {
+	ch := make(chan Event, h.buffer)
+	dropped := make(chan struct{})
+	sub := &Subscription{C: ch, Dropped: dropped, Closing: h.closing, hub: h, topic: topic, ch: ch, dropped: dropped}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Subscription]struct{})
+	}
+	h.topics[topic][sub] = struct{}{}
+	return sub
+}
+
+// Publish sends an event to every subscriber of topic on every instance.
+// Failures are logged; live updates are best effort.
+func (h *Hub) Publish(topic, eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Println("Failed to encode event:", err)
+		return
+	}
+	payload, err := json.Marshal(Event{Type: eventType, Data: raw})
+	if err != nil {
+		log.Println("Failed to encode event:", err)
+		return
+	}
+	if err := h.broker.Publish(topic, payload); err != nil {
+		log.Println("Failed to publish event:", err)
+	}
+}
+
+// Shutdown tells every subscriber on this instance, current and future,
+// that the server is going away, so their clients reconnect to another
+// instance. Called on shutdown so open streams don't hold it up.
+func (h *Hub) Shutdown() {
+	if h == nil {
+		return
+	}
+	h.shutdown.Do(func() { close(h.closing) })
+}
+
+// deliver hands an event from the broker to local subscribers without
+// ever blocking: a subscriber whose buffer is full is dropped.
+func (h *Hub) deliver(topic string, payload []byte) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		log.Println("Failed to decode event:", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.drop()
+		}
+	}
+}
+
+// PostCommentsTopic carries comment.created, comment.updated and
+// comment.deleted events of one post.
+func PostCommentsTopic(postID uint) string {
+	return "post:" + strconv.FormatUint(uint64(postID), 10) + ":comments"
+}
+
+// UserNotificationsTopic carries the notifications of one user.
+func UserNotificationsTopic(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10) + ":notifications"
+}
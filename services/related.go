@@ -0,0 +1,175 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+)
+
+// Weights of the three similarity signals; they add up to 1.
+const (
+	relatedTagWeight     = 0.5
+	relatedContentWeight = 0.35
+	relatedAuthorWeight  = 0.15
+	relatedPerPost       = 10
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "had": true, "her": true,
+	"was": true, "one": true, "our": true, "out": true, "has": true, "his": true,
+	"how": true, "its": true, "who": true, "did": true, "yes": true, "she": true,
+	"him": true, "this": true, "that": true, "with": true, "have": true, "from": true,
+	"they": true, "will": true, "would": true, "there": true, "their": true,
+	"what": true, "about": true, "which": true, "when": true, "your": true,
+	"were": true, "been": true, "into": true, "than": true, "then": true,
+	"them": true, "these": true, "some": true, "just": true, "also": true,
+}
+
+// StartRelatedJob rebuilds related-post rankings now and then every
+// RELATED_INTERVAL (default 1h) in the background.
+func StartRelatedJob() {
+	go runEvery(config.GetEnvDuration("RELATED_INTERVAL", time.Hour), "related posts", RebuildRelatedPosts)
+}
+
+type postFeatures struct {
+	id     uint
+	userID uint
+	tags   map[uint]bool
+	terms  map[string]float64 // normalized term frequencies
+	norm   float64
+}
+
+// RebuildRelatedPosts scores every pair of published public posts and
+// stores the best matches of each post in related_posts.
+func RebuildRelatedPosts() error {
+	var posts []models.Blog
+	if err := config.DB.
+		Preload("Tags").
+		Where("published = ? AND visibility = ? AND hidden = ?", true, models.VisibilityPublic, false).
+		Find(&posts).Error; err != nil {
+		return err
+	}
+
+	features := make([]postFeatures, len(posts))
+	for i, post := range posts {
+		features[i] = extractFeatures(post)
+	}
+
+	now := time.Now()
+	var rows []models.RelatedPost
+	for i := range features {
+		var candidates []models.RelatedPost
+		for j := range features {
+			if i == j {
+				continue
+			}
+			score := similarity(&features[i], &features[j])
+			if score <= 0 {
+				continue
+			}
+			candidates = append(candidates, models.RelatedPost{
+				PostID:    features[i].id,
+				RelatedID: features[j].id,
+				Score:     score,
+				CreatedAt: now,
+			})
+		}
+		sort.Slice(candidates, func(a, b int) bool { return candidates[a].Score > candidates[b].Score })
+		if len(candidates) > relatedPerPost {
+			candidates = candidates[:relatedPerPost]
+		}
+		rows = append(rows, candidates...)
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.RelatedPost{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rows, 500).Error
+	})
+}
+
+func extractFeatures(post models.Blog) postFeatures {
+	f := postFeatures{
+		id:     post.ID,
+		userID: post.UserID,
+		tags:   make(map[uint]bool, len(post.Tags)),
+		terms:  make(map[string]float64),
+	}
+	for _, tag := range post.Tags {
+		f.tags[tag.ID] = true
+	}
+
+	text := htmlTagPattern.ReplaceAllString(post.Title+" "+post.Content, " ")
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	total := 0.0
+	for _, w := range words {
+		if len(w) < 3 || stopWords[w] {
+			continue
+		}
+		f.terms[w]++
+		total++
+	}
+	for term, n := range f.terms {
+		tf := n / total
+		f.terms[term] = tf
+		f.norm += tf * tf
+	}
+	f.norm = math.Sqrt(f.norm)
+	return f
+}
+
+// similarity combines tag overlap (Jaccard), content cosine similarity and
+// a same-author bonus into a score between 0 and 1.
+func similarity(a, b *postFeatures) float64 {
+	var tagScore float64
+	if len(a.tags) > 0 && len(b.tags) > 0 {
+		shared := 0
+		for id := range a.tags {
+			if b.tags[id] {
+				shared++
+			}
+		}
+		tagScore = float64(shared) / float64(len(a.tags)+len(b.tags)-shared)
+	}
+
+	var contentScore float64
+	if a.norm > 0 && b.norm > 0 {
+		small, large := a.terms, b.terms
+		if len(small) > len(large) {
+			small, large = large, small
+		}
+		dot := 0.0
+		for term, tf := range small {
+			dot += tf * large[term]
+		}
+		contentScore = dot / (a.norm * b.norm)
+	}
+
+	var authorScore float64
+	if a.userID == b.userID {
+		authorScore = 1
+	}
+
+	// An author match alone is not enough to call two posts related
+	if tagScore == 0 && contentScore == 0 {
+		return 0
+	}
+	return relatedTagWeight*tagScore + relatedContentWeight*contentScore + relatedAuthorWeight*authorScore
+}
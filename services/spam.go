@@ -0,0 +1,240 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+)
+
+// SpamSubmission is what gets scored: a new comment or a registration.
+type SpamSubmission struct {
+	Kind     string // models.SpamSubjectComment or models.SpamSubjectUser
+	UserID   uint   // author of a comment; 0 for registrations
+	IP       string
+	Content  string
+	Email    string
+	Username string
+	Honeypot string // hidden form field that only bots fill in
+}
+
+// SpamResult is the combined score of a submission and what to do with it.
+type SpamResult struct {
+	Score   float64            `json:"score"`
+	Action  string             `json:"action"`
+	Signals map[string]float64 `json:"signals"` // per-check score before weighting
+}
+
+// SpamScorer decides whether a submission is accepted, held for a
+// moderator or rejected outright.
+type SpamScorer interface {
+	Score(sub SpamSubmission) (SpamResult, error)
+}
+
+// SpamHeuristic is one signal of the built-in scorer. Evaluate returns a
+// score between 0 (clean) and 1 (certainly spam).
+type SpamHeuristic interface {
+	Name() string
+	Evaluate(sub SpamSubmission) (float64, error)
+}
+
+// Spam is the process-wide scorer. It stays nil until StartSpamFilter is
+// called, and a nil scorer accepts everything.
+var Spam SpamScorer
+
+// StartSpamFilter installs the built-in heuristic scorer configured from
+// the environment. Replace Spam afterwards to plug in another scorer.
+func StartSpamFilter() {
+	Spam = NewHeuristicSpamScorer(
+		config.GetEnvFloat("SPAM_HOLD_SCORE", 0.5),
+		config.GetEnvFloat("SPAM_REJECT_SCORE", 1.0),
+		LinkDensityCheck{MaxLinks: config.GetEnvInt("SPAM_MAX_LINKS", 3)},
+		BlocklistCheck{
+			Words:   config.GetEnvList("SPAM_BLOCKED_WORDS"),
+			Domains: config.GetEnvList("SPAM_BLOCKED_DOMAINS"),
+		},
+		DuplicateCheck{Window: config.GetEnvDuration("SPAM_DUPLICATE_WINDOW", 24*time.Hour)},
+		NewVelocityCheck(
+			config.GetEnvInt("SPAM_VELOCITY_LIMIT", 5),
+			config.GetEnvDuration("SPAM_VELOCITY_WINDOW", time.Minute),
+		),
+		HoneypotCheck{},
+	)
+}
+
+// ScoreSpam runs the configured scorer, accepting everything when none is
+// installed.
+func ScoreSpam(sub SpamSubmission) (SpamResult, error) {
+	if Spam == nil {
+		return SpamResult{Action: models.SpamAccept}, nil
+	}
+	return Spam.Score(sub)
+}
+
+// HeuristicSpamScorer adds up weighted heuristic scores and compares the
+// total to the hold and reject thresholds.
+type HeuristicSpamScorer struct {
+	checks  []SpamHeuristic
+	weights map[string]float64
+	hold    float64
+	reject  float64
+}
+
+// NewHeuristicSpamScorer builds a scorer from the given checks. Each check
+// is weighted by SPAM_WEIGHT_<NAME> (default 1).
+func NewHeuristicSpamScorer(hold, reject float64, checks ...SpamHeuristic) *HeuristicSpamScorer {
+	weights := make(map[string]float64, len(checks))
+	for _, check := range checks {
+		weights[check.Name()] = config.GetEnvFloat("SPAM_WEIGHT_"+strings.ToUpper(check.Name()), 1)
+	}
+	return &HeuristicSpamScorer{checks: checks, weights: weights, hold: hold, reject: reject}
+}
+
+func (s *HeuristicSpamScorer) Score(sub SpamSubmission) (SpamResult, error) {
+	result := SpamResult{Action: models.SpamAccept, Signals: make(map[string]float64)}
+	for _, check := range s.checks {
+		score, err := check.Evaluate(sub)
+		if err != nil {
+			return result, err
+		}
+		if score > 0 {
+			result.Signals[check.Name()] = score
+			result.Score += score * s.weights[check.Name()]
+		}
+	}
+
+	switch {
+	case result.Score >= s.reject:
+		result.Action = models.SpamReject
+	case result.Score >= s.hold:
+		result.Action = models.SpamHold
+	}
+	return result, nil
+}
+
+// RecordSpamCheck stores how a submission was scored so moderators'
+// verdicts can later be compared against it.
+func RecordSpamCheck(db *gorm.DB, subjectType string, subjectID uint, sub SpamSubmission, result SpamResult) error {
+	signals, err := json.Marshal(result.Signals)
+	if err != nil {
+		return err
+	}
+	return db.Create(&models.SpamCheck{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		UserID:      sub.UserID,
+		IP:          sub.IP,
+		Score:       result.Score,
+		Action:      result.Action,
+		Signals:     string(signals),
+	}).Error
+}
+
+// RecordSpamVerdict attaches a moderator's spam/ham verdict to the latest
+// check of a subject. Subjects that were never scored are ignored.
+func RecordSpamVerdict(db *gorm.DB, subjectType string, subjectID uint, verdict string, moderatorID uint) error {
+	var check models.SpamCheck
+	err := db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).
+		Order("id desc").
+		First(&check).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return db.Model(&check).Updates(map[string]interface{}{
+		"verdict":    verdict,
+		"verdict_by": moderatorID,
+		"verdict_at": now,
+	}).Error
+}
+
+// SpamCheckStat shows how well one heuristic agrees with moderators:
+// Precision is the share of its firings that were confirmed spam and
+// Recall the share of confirmed spam it fired on.
+type SpamCheckStat struct {
+	Check     string  `json:"check"`
+	Weight    float64 `json:"weight,omitempty"`
+	FiredSpam int     `json:"fired_spam"`
+	FiredHam  int     `json:"fired_ham"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+}
+
+// SpamStats summarizes all checks that have a verdict.
+type SpamStats struct {
+	Reviewed       int             `json:"reviewed"`
+	Spam           int             `json:"spam"`
+	Ham            int             `json:"ham"`
+	FalsePositives int             `json:"false_positives"` // held or rejected, then marked ham
+	FalseNegatives int             `json:"false_negatives"` // accepted, then marked spam
+	Checks         []SpamCheckStat `json:"checks"`
+}
+
+// ComputeSpamStats compares recorded scores with moderator verdicts.
+func ComputeSpamStats() (SpamStats, error) {
+	var stats SpamStats
+	var checks []models.SpamCheck
+	if err := config.DB.Where("verdict <> ''").Find(&checks).Error; err != nil {
+		return stats, err
+	}
+
+	byCheck := make(map[string]*SpamCheckStat)
+	for _, check := range checks {
+		stats.Reviewed++
+		isSpam := check.Verdict == models.SpamVerdictSpam
+		if isSpam {
+			stats.Spam++
+			if check.Action == models.SpamAccept {
+				stats.FalseNegatives++
+			}
+		} else {
+			stats.Ham++
+			if check.Action != models.SpamAccept {
+				stats.FalsePositives++
+			}
+		}
+
+		var signals map[string]float64
+		if err := json.Unmarshal([]byte(check.Signals), &signals); err != nil {
+			continue
+		}
+		for name := range signals {
+			stat, ok := byCheck[name]
+			if !ok {
+				stat = &SpamCheckStat{Check: name}
+				byCheck[name] = stat
+			}
+			if isSpam {
+				stat.FiredSpam++
+			} else {
+				stat.FiredHam++
+			}
+		}
+	}
+
+	weights := map[string]float64{}
+	if scorer, ok := Spam.(*HeuristicSpamScorer); ok {
+		weights = scorer.weights
+	}
+	stats.Checks = []SpamCheckStat{}
+	for _, stat := range byCheck {
+		stat.Weight = weights[stat.Check]
+		stat.Precision = float64(stat.FiredSpam) / float64(stat.FiredSpam+stat.FiredHam)
+		if stats.Spam > 0 {
+			stat.Recall = float64(stat.FiredSpam) / float64(stats.Spam)
+		}
+		stats.Checks = append(stats.Checks, *stat)
+	}
+	sort.Slice(stats.Checks, func(i, j int) bool { return stats.Checks[i].Check < stats.Checks[j].Check })
+	return stats, nil
+}
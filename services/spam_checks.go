@@ -0,0 +1,179 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+)
+
+// LinkPattern matches anything that looks like a link in user content.
+var LinkPattern = regexp.MustCompile(`(?i)(https?://|www\.)[^\s<>"]+`)
+
+// LinkDensityCheck flags content made mostly of links. More than MaxLinks
+// links scores 1; fewer scores by the share of words that are links.
+type LinkDensityCheck struct {
+	MaxLinks int
+}
+
+func (LinkDensityCheck) Name() string { return "links" }
+
+func (l LinkDensityCheck) Evaluate(sub SpamSubmission) (float64, error) {
+	links := len(LinkPattern.FindAllString(sub.Content, -1))
+	if links == 0 {
+		return 0, nil
+	}
+	if links > l.MaxLinks {
+		return 1, nil
+	}
+	words := len(strings.Fields(sub.Content))
+	density := float64(links) / float64(words)
+	// A link or two in a real sentence is fine; a bare link is not
+	if density >= 0.5 {
+		return 0.6, nil
+	}
+	return density, nil
+}
+
+// BlocklistCheck flags content containing blocked words, and links or
+// email addresses on blocked domains (subdomains included).
+type BlocklistCheck struct {
+	Words   []string
+	Domains []string
+}
+
+func (BlocklistCheck) Name() string { return "blocklist" }
+
+func (b BlocklistCheck) Evaluate(sub SpamSubmission) (float64, error) {
+	text := strings.ToLower(sub.Content + " " + sub.Username)
+	for _, word := range b.Words {
+		if strings.Contains(text, word) {
+			return 1, nil
+		}
+	}
+
+	var hosts []string
+	for _, link := range LinkPattern.FindAllString(sub.Content, -1) {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		if u, err := url.Parse(link); err == nil {
+			hosts = append(hosts, strings.ToLower(u.Hostname()))
+		}
+	}
+	if at := strings.LastIndex(sub.Email, "@"); at >= 0 {
+		hosts = append(hosts, strings.ToLower(sub.Email[at+1:]))
+	}
+	for _, host := range hosts {
+		for _, domain := range b.Domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// DuplicateCheck flags a comment whose text the same user already posted
+// within Window, or that several other users posted.
+type DuplicateCheck struct {
+	Window time.Duration
+}
+
+func (DuplicateCheck) Name() string { return "duplicate" }
+
+func (d DuplicateCheck) Evaluate(sub SpamSubmission) (float64, error) {
+	content := strings.TrimSpace(sub.Content)
+	if sub.Kind != models.SpamSubjectComment || content == "" {
+		return 0, nil
+	}
+
+	var own, others int64
+	since := time.Now().Add(-d.Window)
+	if err := config.DB.Model(&models.Comment{}).
+		Where("content = ? AND user_id = ? AND created_at > ?", content, sub.UserID, since).
+		Count(&own).Error; err != nil {
+		return 0, err
+	}
+	if own > 0 {
+		return 1, nil
+	}
+	if err := config.DB.Model(&models.Comment{}).
+		Where("content = ? AND user_id <> ? AND created_at > ?", content, sub.UserID, since).
+		Count(&others).Error; err != nil {
+		return 0, err
+	}
+	if others >= 2 {
+		return 0.8, nil
+	}
+	return 0, nil
+}
+
+// VelocityCheck flags users (or, for registrations, IP addresses) that
+// submit more than limit times within window. It keeps its own in-memory
+// log, so each server instance counts separately.
+type VelocityCheck struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	recent map[string][]time.Time
+}
+
+func NewVelocityCheck(limit int, window time.Duration) *VelocityCheck {
+	return &VelocityCheck{limit: limit, window: window, recent: make(map[string][]time.Time)}
+}
+
+func (*VelocityCheck) Name() string { return "velocity" }
+
+func (v *VelocityCheck) Evaluate(sub SpamSubmission) (float64, error) {
+	key := sub.Kind + "|ip:" + sub.IP
+	if sub.UserID != 0 {
+		key = sub.Kind + "|user:" + strconv.FormatUint(uint64(sub.UserID), 10)
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-v.window)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Drop expired entries, including other keys' so the map can't grow
+	// without bound
+	for k, times := range v.recent {
+		kept := times[:0]
+		for _, t := range times {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(v.recent, k)
+		} else {
+			v.recent[k] = kept
+		}
+	}
+
+	v.recent[key] = append(v.recent[key], now)
+	if len(v.recent[key]) > v.limit {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// HoneypotCheck flags submissions that filled in the hidden honeypot field.
+type HoneypotCheck struct{}
+
+func (HoneypotCheck) Name() string { return "honeypot" }
+
+func (HoneypotCheck) Evaluate(sub SpamSubmission) (float64, error) {
+	if strings.TrimSpace(sub.Honeypot) != "" {
+		return 1, nil
+	}
+	return 0, nil
+}
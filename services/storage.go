@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"BlogApp/config"
+)
+
+// ErrObjectNotFound is returned by Storage.Get for a missing key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage keeps uploaded media. Keys are slash-separated paths such as
+// "avatars/ab/cd/abcd....jpg"; URL turns a key into the address clients
+// fetch it from.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// Media is the process-wide media store. It stays nil until StartStorage
+// is called.
+var Media Storage
+
+// StartStorage picks the media store from STORAGE_DRIVER: "local" (the
+// default) keeps files under MEDIA_DIR, "s3" talks to any S3-compatible
+// service. MEDIA_PUBLIC_URL overrides where clients load media from, for
+// example a CDN in front of the bucket.
+func StartStorage() {
+	publicURL := config.GetEnv("MEDIA_PUBLIC_URL", "")
+	switch driver := config.GetEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		if publicURL == "" {
+			publicURL = "/uploads"
+		}
+		Media = &LocalStorage{Dir: config.GetEnv("MEDIA_DIR", "./uploads"), PublicURL: publicURL}
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:  config.GetEnv("S3_ENDPOINT", ""),
+			Region:    config.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    config.GetEnv("S3_BUCKET", ""),
+			AccessKey: config.GetEnv("S3_ACCESS_KEY", ""),
+			SecretKey: config.GetEnv("S3_SECRET_KEY", ""),
+			PathStyle: config.GetEnvBool("S3_PATH_STYLE", false),
+			PublicURL: publicURL,
+		})
+		if err != nil {
+			log.Fatal("Failed to configure S3 storage:", err)
+		}
+		Media = s3
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q, expected local or s3", driver)
+	}
+}
+
+// ContentKey names an object after the SHA-256 of its content, so the same
+// file always gets the same key on every instance and identical uploads
+// are stored once. ext includes the dot, e.g. ".jpg".
+func ContentKey(prefix string, data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])
+	return path.Join(prefix, name[0:2], name[2:4], name+strings.ToLower(ext))
+}
+
+// LocalStorage keeps media in a directory on this machine. Instances only
+// share it if the directory is shared, e.g. a network volume.
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+// file maps a key to its path below Dir, refusing keys that would escape
+// the directory.
+func (s *LocalStorage) file(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", errors.New("invalid object key")
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial
+// object.
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	name, err := s.file(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.file(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Delete removes the object; deleting a missing key is not an error.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.file(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return strings.TrimSuffix(s.PublicURL, "/") + "/" + key
+}
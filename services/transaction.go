@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type afterCommitKey struct{}
+
+// afterCommitHooks collects the work to do once a transaction commits
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// Transaction runs fn in a transaction, like db.Transaction, then runs the
+// functions registered with AfterCommit if it committed. Nested calls join
+// the outermost transaction's hooks.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if hooksOf(db) != nil {
+		return db.Transaction(fn)
+	}
+
+	hooks := &afterCommitHooks{}
+	ctx := context.WithValue(db.Statement.Context, afterCommitKey{}, hooks)
+	if err := db.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	for _, f := range hooks.fns {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit defers f until the Transaction db belongs to has committed,
+// so clients are never told about changes that get rolled back. Outside a
+// Transaction, f runs right away.
+func AfterCommit(db *gorm.DB, f func()) {
+	if hooks := hooksOf(db); hooks != nil {
+		hooks.fns = append(hooks.fns, f)
+		return
+	}
+	f()
+}
+
+func hooksOf(db *gorm.DB) *afterCommitHooks {
+	if db.Statement == nil || db.Statement.Context == nil {
+		return nil
+	}
+	hooks, _ := db.Statement.Context.Value(afterCommitKey{}).(*afterCommitHooks)
+	return hooks
+}
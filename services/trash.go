@@ -0,0 +1,148 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+)
+
+// TrashRetention is how long soft-deleted posts and comments stay in the
+// trash before the retention job purges them (TRASH_RETENTION_DAYS).
+func TrashRetention() time.Duration {
+	return time.Duration(config.GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// StartTrashRetentionJob purges expired trash now and then every
+// TRASH_PURGE_INTERVAL (default 6h) in the background.
+func StartTrashRetentionJob() {
+	go runEvery(config.GetEnvDuration("TRASH_PURGE_INTERVAL", 6*time.Hour), "trash retention", PurgeExpiredTrash)
+}
+
+// SoftDeletePost moves a post to the trash together with its live
+// comments. Both get the same deleted_at, which is how RestorePost tells
+// cascaded comments apart from ones deleted on their own.
+func SoftDeletePost(tx *gorm.DB, blog *models.Blog) error {
+	now := time.Now()
+	if err := tx.Model(&models.Comment{}).Where("post_id = ?", blog.ID).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(blog).Update("deleted_at", now).Error
+}
+
+// RestorePost brings a trashed post back along with the comments that
+// were deleted with it.
+func RestorePost(tx *gorm.DB, blog *models.Blog) error {
+	if err := tx.Unscoped().Model(&models.Comment{}).
+		Where("post_id = ? AND deleted_at = ?", blog.ID, blog.DeletedAt.Time).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(blog).Update("deleted_at", nil).Error
+}
+
+// PurgePost permanently deletes a post, its comments and everything else
+// that refers to it.
+func PurgePost(tx *gorm.DB, postID uint) error {
+	var commentIDs []uint
+	if err := tx.Unscoped().Model(&models.Comment{}).Where("post_id = ?", postID).Pluck("id", &commentIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range commentIDs {
+		if err := PurgeComment(tx, id); err != nil {
+			return err
+		}
+	}
+
+	dependents := []interface{}{
+		&models.PostCollaborator{}, &models.ReviewComment{}, &models.PreviewLink{},
+		&models.PostAutosave{}, &models.PostViewDaily{},
+		&models.PostStat{},
+	}
+	for _, model := range dependents {
+		if err := tx.Where("post_id = ?", postID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("post_id = ? OR related_id = ?", postID, postID).Delete(&models.RelatedPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("target_type = ? AND target_id = ?", models.ReportTargetPost, postID).Delete(&models.Report{}).Error; err != nil {
+		return err
+	}
+	if err := DeleteMentions(tx, models.MentionSourcePost, postID); err != nil {
+		return err
+	}
+	if err := DeleteNotificationsAbout(tx, models.NotificationSubjectPost, postID); err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM blog_tags WHERE blog_id = ?", postID).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&models.Blog{}, postID).Error
+}
+
+// PurgeComment permanently deletes a comment and everything that refers to
+// it.
+func PurgeComment(tx *gorm.DB, commentID uint) error {
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.CommentReaction{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.CommentVote{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Blog{}).Where("pinned_comment_id = ?", commentID).UpdateColumn("pinned_comment_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.CommentRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("target_type = ? AND target_id = ?", models.ReportTargetComment, commentID).Delete(&models.Report{}).Error; err != nil {
+		return err
+	}
+	if err := DeleteMentions(tx, models.MentionSourceComment, commentID); err != nil {
+		return err
+	}
+	if err := DeleteNotificationsAbout(tx, models.NotificationSubjectComment, commentID); err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&models.Comment{}, commentID).Error
+}
+
+// PurgeExpiredTrash permanently deletes posts and comments that have been
+// in the trash longer than TrashRetention.
+func PurgeExpiredTrash() error {
+	cutoff := time.Now().Add(-TrashRetention())
+
+	var postIDs []uint
+	if err := config.DB.Unscoped().Model(&models.Blog{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range postIDs {
+		if err := config.DB.Transaction(func(tx *gorm.DB) error { return PurgePost(tx, id) }); err != nil {
+			return err
+		}
+	}
+
+	var commentIDs []uint
+	if err := config.DB.Unscoped().Model(&models.Comment{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &commentIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range commentIDs {
+		if err := config.DB.Transaction(func(tx *gorm.DB) error { return PurgeComment(tx, id) }); err != nil {
+			return err
+		}
+	}
+
+	if len(postIDs) > 0 || len(commentIDs) > 0 {
+		log.Printf("Purged %d posts and %d comments from the trash", len(postIDs), len(commentIDs))
+	}
+	return nil
+}
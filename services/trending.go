@@ -0,0 +1,99 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Engagement weights and decay used for the trending score. A post's score
+// is its weighted engagement divided by (age in hours + 2) ^ gravity, so
+// recent posts with engagement outrank older ones.
+const (
+	trendingViewWeight     = 1.0
+	trendingCommentWeight  = 3.0
+	trendingReactionWeight = 2.0
+	trendingGravity        = 1.5
+)
+
+// StartTrendingJob refreshes post stats now and then every
+// TRENDING_INTERVAL (default 15m) in the background.
+func StartTrendingJob() {
+	go runEvery(config.GetEnvDuration("TRENDING_INTERVAL", 15*time.Minute), "trending", RefreshPostStats)
+}
+
+type postCount struct {
+	PostID uint
+	Total  int64
+}
+
+// RefreshPostStats recomputes the engagement totals and trending score of
+// every post and stores them in post_stats.
+func RefreshPostStats() error {
+	var posts []models.Blog
+	if err := config.DB.Select("id", "created_at").Find(&posts).Error; err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		return nil
+	}
+
+	comments, err := countByPost(config.DB.Model(&models.Comment{}).Where("is_deleted = ? AND status = ? AND hidden = ?", false, models.CommentApproved, false).Select("post_id, COUNT(*) AS total").Group("post_id"))
+	if err != nil {
+		return err
+	}
+	// Posts have no reactions of their own; a post's discussion counts
+	reactions, err := countByPost(config.DB.Model(&models.CommentReaction{}).
+		Joins("JOIN comments ON comments.id = comment_reactions.comment_id AND comments.deleted_at IS NULL").
+		Where("comments.is_deleted = ? AND comments.status = ? AND comments.hidden = ?", false, models.CommentApproved, false).
+		Select("comments.post_id AS post_id, COUNT(*) AS total").Group("comments.post_id"))
+	if err != nil {
+		return err
+	}
+	views, err := countByPost(config.DB.Model(&models.PostViewDaily{}).Select("post_id, SUM(views) AS total").Group("post_id"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stats := make([]models.PostStat, 0, len(posts))
+	for _, post := range posts {
+		stat := models.PostStat{
+			PostID:    post.ID,
+			Views:     uint64(views[post.ID]),
+			Comments:  comments[post.ID],
+			Reactions: reactions[post.ID],
+			UpdatedAt: now,
+		}
+		stat.TrendingScore = TrendingScore(stat, post.CreatedAt, now)
+		stats = append(stats, stat)
+	}
+
+	return config.DB.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&stats, 500).Error
+}
+
+// TrendingScore applies the time decay to a post's engagement.
+func TrendingScore(stat models.PostStat, createdAt, now time.Time) float64 {
+	engagement := trendingViewWeight*float64(stat.Views) +
+		trendingCommentWeight*float64(stat.Comments) +
+		trendingReactionWeight*float64(stat.Reactions)
+	ageHours := math.Max(now.Sub(createdAt).Hours(), 0)
+	return engagement / math.Pow(ageHours+2, trendingGravity)
+}
+
+func countByPost(query *gorm.DB) (map[uint]int64, error) {
+	var rows []postCount
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.PostID] = row.Total
+	}
+	return counts, nil
+}
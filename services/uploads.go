@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"BlogApp/config"
+)
+
+// Upload validation failures. Handlers map them to 4xx responses.
+var (
+	ErrUploadEmpty    = errors.New("file is empty")
+	ErrUploadTooLarge = errors.New("file is too large")
+	ErrUploadType     = errors.New("file type is not allowed")
+	ErrUploadCorrupt  = errors.New("file is not a valid image")
+)
+
+// UploadKind says what may be uploaded for one purpose: the allowed
+// content types, sniffed from the file itself, and the size limit.
+type UploadKind struct {
+	Name     string
+	MaxBytes int64
+	Types    map[string]string // content type -> file extension
+}
+
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// AvatarUpload is a profile picture (UPLOAD_AVATAR_MAX_BYTES, default 2 MB).
+func AvatarUpload() UploadKind {
+	return UploadKind{Name: "avatars", MaxBytes: int64(config.GetEnvInt("UPLOAD_AVATAR_MAX_BYTES", 2<<20)), Types: imageTypes}
+}
+
+// PostImageUpload is an image shown in a post (UPLOAD_POST_IMAGE_MAX_BYTES,
+// default 8 MB).
+func PostImageUpload() UploadKind {
+	return UploadKind{Name: "posts", MaxBytes: int64(config.GetEnvInt("UPLOAD_POST_IMAGE_MAX_BYTES", 8<<20)), Types: imageTypes}
+}
+
+// Upload is a validated file ready to be stored under Key. The client's
+// file name and content type are never used.
+type Upload struct {
+	Data        []byte
+	ContentType string
+	Key         string
+}
+
+// ReadUpload reads an uploaded file, enforcing the kind's size limit and
+// allowing only the content types its magic bytes identify as allowed.
+// Metadata is stripped before the key is derived.
+func ReadUpload(file *multipart.FileHeader, kind UploadKind) (Upload, error) {
+	if file.Size > kind.MaxBytes {
+		return Upload{}, ErrUploadTooLarge
+	}
+	f, err := file.Open()
+	if err != nil {
+		return Upload{}, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, kind.MaxBytes+1))
+	if err != nil {
+		return Upload{}, err
+	}
+	if len(data) == 0 {
+		return Upload{}, ErrUploadEmpty
+	}
+	if int64(len(data)) > kind.MaxBytes {
+		return Upload{}, ErrUploadTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := kind.Types[contentType]
+	if !ok {
+		return Upload{}, ErrUploadType
+	}
+	if data, err = StripMetadata(contentType, data); err != nil {
+		return Upload{}, ErrUploadCorrupt
+	}
+	return Upload{Data: data, ContentType: contentType, Key: ContentKey(kind.Name, data, ext)}, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"BlogApp/config"
+	"BlogApp/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Views is the process-wide view counter. It stays nil until
+// StartViewCounter is called, and a nil counter silently drops views.
+var Views *ViewCounter
+
+type viewBucket struct {
+	PostID uint
+	Day    time.Time
+}
+
+// ViewCounter deduplicates post views per visitor and buffers the counts in
+// memory, writing them to the database in batches so that reading a post
+// never turns into a write.
+type ViewCounter struct {
+	mu         sync.Mutex
+	window     time.Duration
+	maxPending int
+	seen       map[string]time.Time // "visitor|post" -> last counted view
+	pending    map[viewBucket]uint64
+	flush      chan struct{}
+}
+
+func NewViewCounter(window time.Duration, maxPending int) *ViewCounter {
+	return &ViewCounter{
+		window:     window,
+		maxPending: maxPending,
+		seen:       make(map[string]time.Time),
+		pending:    make(map[viewBucket]uint64),
+		flush:      make(chan struct{}, 1),
+	}
+}
+
+// StartViewCounter creates the global counter from the environment and
+// starts its background flusher.
+func StartViewCounter() {
+	Views = NewViewCounter(
+		config.GetEnvDuration("VIEW_DEDUPE_WINDOW", 30*time.Minute),
+		config.GetEnvInt("VIEW_MAX_PENDING", 1000),
+	)
+	go Views.Run(config.GetEnvDuration("VIEW_FLUSH_INTERVAL", 10*time.Second))
+}
+
+// Record counts a view of postID by visitor unless the same visitor was
+// already counted for that post within the dedupe window.
+func (v *ViewCounter) Record(postID uint, visitor string) {
+	if v == nil {
+		return
+	}
+	now := time.Now()
+	key := visitor + "|" + strconv.FormatUint(uint64(postID), 10)
+
+	v.mu.Lock()
+	if last, ok := v.seen[key]; ok && now.Sub(last) < v.window {
+		v.mu.Unlock()
+		return
+	}
+	v.seen[key] = now
+	y, m, d := now.Date()
+	v.pending[viewBucket{PostID: postID, Day: time.Date(y, m, d, 0, 0, 0, 0, time.Local)}]++
+	full := len(v.pending) >= v.maxPending
+	v.mu.Unlock()
+
+	if full {
+		select {
+		case v.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes buffered views every interval, or earlier when the buffer
+// fills up. It blocks forever and is meant to run in its own goroutine.
+func (v *ViewCounter) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-v.flush:
+		}
+		v.Flush()
+	}
+}
+
+// Flush writes all buffered counts to the database. Counts that fail to
+// save are put back into the buffer for the next attempt. Call it once more
+// on shutdown, after the server stopped recording views.
+func (v *ViewCounter) Flush() {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	batch := v.pending
+	v.pending = make(map[viewBucket]uint64)
+	cutoff := time.Now().Add(-v.window)
+	for key, last := range v.seen {
+		if last.Before(cutoff) {
+			delete(v.seen, key)
+		}
+	}
+	v.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	rows := make([]models.PostViewDaily, 0, len(batch))
+	for bucket, n := range batch {
+		rows = append(rows, models.PostViewDaily{PostID: bucket.PostID, Day: bucket.Day, Views: n})
+	}
+
+	err := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + VALUES(views)")}),
+	}).CreateInBatches(&rows, 200).Error
+	if err != nil {
+		log.Println("Failed to flush post views:", err)
+		v.mu.Lock()
+		for bucket, n := range batch {
+			v.pending[bucket] += n
+		}
+		v.mu.Unlock()
+	}
+}